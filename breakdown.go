@@ -0,0 +1,169 @@
+package paynow
+
+import "math"
+
+// AdjustmentKind classifies an Adjustment applied to a Payment.
+type AdjustmentKind string
+
+const (
+	// AdjustmentDiscount reduces the amount payable.
+	AdjustmentDiscount AdjustmentKind = "discount"
+
+	// AdjustmentTax is a tax such as VAT, charged on the discounted subtotal.
+	AdjustmentTax AdjustmentKind = "tax"
+
+	// AdjustmentFee is a merchant fee or surcharge, charged on the subtotal
+	// after discounts and taxes.
+	AdjustmentFee AdjustmentKind = "fee"
+)
+
+// Adjustment is a discount, tax or fee applied to a Payment, or a discount
+// applied to a single CartItem. It is either a fixed Amount or, when Percent is
+// non-zero, a percentage of the running total it applies to.
+type Adjustment struct {
+	// Kind is what the adjustment is. It is ignored for line discounts.
+	Kind AdjustmentKind `json:"kind,omitempty"`
+
+	// Label describes the adjustment on receipts, for example "VAT 15%".
+	Label string `json:"label,omitempty"`
+
+	// Amount is a fixed amount. It is used when Percent is zero.
+	Amount float64 `json:"amount,omitempty"`
+
+	// Percent is a percentage (15 for 15%) of the running total.
+	Percent float64 `json:"percent,omitempty"`
+
+	// Inclusive marks a tax as already included in item prices. It is shown in
+	// the Breakdown but not added to the total.
+	Inclusive bool `json:"inclusive,omitempty"`
+}
+
+// valueOf returns the adjustment's value in cents when applied to base cents.
+func (a Adjustment) valueOf(base int64) int64 {
+	if a.Percent != 0 {
+		return percentOf(base, a.Percent)
+	}
+	return toCents(a.Amount)
+}
+
+// Breakdown itemises how a Payment's total is made up, for rendering on
+// receipts. Every amount is rounded to the cent, and Total is exactly the
+// amount sent to Paynow.
+//
+// The total is computed in this order: each line's subtotal less its line
+// discount, summed into Subtotal; cart-level discounts, each applied to the
+// running total; taxes on the discounted subtotal; and finally fees on the
+// amount after taxes. Discounts never take a line or the cart below zero.
+type Breakdown struct {
+	// Lines has one entry per cart item, in the order they were added.
+	Lines []BreakdownLine
+
+	// Subtotal is the sum of every line's Net amount.
+	Subtotal float64
+
+	// Discounts, Taxes and Fees list the cart-level adjustments and the value
+	// each one contributed.
+	Discounts []AppliedAdjustment
+	Taxes     []AppliedAdjustment
+	Fees      []AppliedAdjustment
+
+	// Total is the amount payable.
+	Total float64
+}
+
+// BreakdownLine is a single cart item within a Breakdown.
+type BreakdownLine struct {
+	Item CartItem
+
+	// Gross is the unit amount times the quantity.
+	Gross float64
+
+	// Discount is the value of the item's line discount.
+	Discount float64
+
+	// Net is Gross less Discount.
+	Net float64
+}
+
+// AppliedAdjustment is an Adjustment together with the value it contributed to
+// a Breakdown. Value is always positive; discounts are subtracted from the
+// total and inclusive taxes are informational.
+type AppliedAdjustment struct {
+	Adjustment
+	Value float64
+}
+
+// breakdown computes the Breakdown for the cart. All arithmetic is done in
+// cents so the result is deterministic.
+func (c *cart) breakdown() Breakdown {
+	var b Breakdown
+
+	var subtotal int64
+	for _, item := range c.items {
+		gross := toCents(item.Amount) * int64(item.units())
+		discount := clampDiscount(item.Discount.valueOf(gross), gross)
+		net := gross - discount
+
+		b.Lines = append(b.Lines, BreakdownLine{
+			Item:     item,
+			Gross:    fromCents(gross),
+			Discount: fromCents(discount),
+			Net:      fromCents(net),
+		})
+		subtotal += net
+	}
+	b.Subtotal = fromCents(subtotal)
+
+	running := subtotal
+	for _, adj := range c.adjustmentsOf(AdjustmentDiscount) {
+		value := clampDiscount(adj.valueOf(running), running)
+		running -= value
+		b.Discounts = append(b.Discounts, AppliedAdjustment{Adjustment: adj, Value: fromCents(value)})
+	}
+
+	taxBase := running
+	for _, adj := range c.adjustmentsOf(AdjustmentTax) {
+		value := adj.valueOf(taxBase)
+		switch {
+		case adj.Inclusive && adj.Percent != 0:
+			// The tax is the part of the base above the pre-tax amount.
+			value = taxBase - int64(math.Round(float64(taxBase)*100/(100+adj.Percent)))
+		case !adj.Inclusive:
+			running += value
+		}
+		b.Taxes = append(b.Taxes, AppliedAdjustment{Adjustment: adj, Value: fromCents(value)})
+	}
+
+	feeBase := running
+	for _, adj := range c.adjustmentsOf(AdjustmentFee) {
+		value := adj.valueOf(feeBase)
+		running += value
+		b.Fees = append(b.Fees, AppliedAdjustment{Adjustment: adj, Value: fromCents(value)})
+	}
+
+	b.Total = fromCents(running)
+	return b
+}
+
+// adjustmentsOf returns the cart-level adjustments of the given kind, in the
+// order they were added.
+func (c *cart) adjustmentsOf(kind AdjustmentKind) []Adjustment {
+	var out []Adjustment
+	for _, adj := range c.adjustments {
+		if adj.Kind == kind {
+			out = append(out, adj)
+		}
+	}
+	return out
+}
+
+// clampDiscount limits a discount to between zero and the amount it applies to.
+func clampDiscount(discount, base int64) int64 {
+	if discount < 0 {
+		return 0
+	}
+	if discount > base {
+		return base
+	}
+	return discount
+}
@@ -0,0 +1,77 @@
+package paynow_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func adjustedPayment() *paynow.Payment {
+	return paynow.NewPayment("INV-1", "buyer@example.com").
+		AddItem(paynow.CartItem{
+			Title:    "T-shirt",
+			Amount:   10.00,
+			Quantity: 2,
+			Discount: paynow.Adjustment{Label: "Bundle", Percent: 10},
+		}).
+		Add("Socks", 5.00).
+		AddDiscount("Voucher", 3.00).
+		AddPercentDiscount("Loyalty", 10).
+		AddTax("VAT 15%", 15).
+		AddFee("Delivery", 0.50).
+		Adjust(paynow.Adjustment{Kind: paynow.AdjustmentFee, Label: "Card surcharge", Percent: 2})
+}
+
+func TestPayment_Breakdown(t *testing.T) {
+	b := adjustedPayment().Breakdown()
+
+	if len(b.Lines) != 2 || b.Lines[0].Gross != 20.00 || b.Lines[0].Discount != 2.00 || b.Lines[0].Net != 18.00 {
+		t.Errorf("Lines = %+v, want T-shirt at 20.00 less 2.00", b.Lines)
+	}
+	if b.Subtotal != 23.00 {
+		t.Errorf("Subtotal = %.2f, want 23.00", b.Subtotal)
+	}
+	if len(b.Discounts) != 2 || b.Discounts[0].Value != 3.00 || b.Discounts[1].Value != 2.00 {
+		t.Errorf("Discounts = %+v, want 3.00 then 10%% of 20.00", b.Discounts)
+	}
+	if len(b.Taxes) != 1 || b.Taxes[0].Value != 2.70 {
+		t.Errorf("Taxes = %+v, want 15%% of 18.00", b.Taxes)
+	}
+	// 2% of 20.70 is 0.414, rounded to 0.41.
+	if len(b.Fees) != 2 || b.Fees[0].Value != 0.50 || b.Fees[1].Value != 0.41 {
+		t.Errorf("Fees = %+v, want 0.50 and 0.41", b.Fees)
+	}
+	if b.Total != 21.61 {
+		t.Errorf("Total = %.2f, want 21.61", b.Total)
+	}
+}
+
+func TestPayment_InclusiveTaxAndClamping(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "buyer@example.com").
+		Add("Meal", 115.00).
+		Adjust(paynow.Adjustment{Kind: paynow.AdjustmentTax, Label: "VAT incl.", Percent: 15, Inclusive: true})
+
+	b := p.Breakdown()
+	if b.Taxes[0].Value != 15.00 || b.Total != 115.00 {
+		t.Errorf("inclusive VAT = %.2f, total = %.2f; want 15.00 within 115.00", b.Taxes[0].Value, b.Total)
+	}
+
+	p.AddDiscount("Too generous", 500)
+	if got := p.Total(); got != 0 {
+		t.Errorf("Total() = %.2f, want discounts clamped at zero", got)
+	}
+}
+
+func TestSend_SignsAdjustedTotal(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	if _, err := newTestClient(doer).Send(context.Background(), adjustedPayment()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	values, _ := url.ParseQuery(doer.capturedBody)
+	if got := values.Get("amount"); got != "21.61" {
+		t.Errorf("request amount = %q, want 21.61", got)
+	}
+}
@@ -1,17 +1,60 @@
 package paynow
 
-import "strings"
-
 // CartItem is a single line item in a Payment's cart.
+//
+// Only Title, Amount, Quantity and Discount affect what is sent to Paynow. SKU,
+// Description, Category and Metadata stay local: they are kept on the Payment,
+// in JSON and in any Store, so receipts and reconciliation can refer back to
+// your catalogue.
 type CartItem struct {
 	// Title is the human-readable name of the item.
-	Title string
+	Title string `json:"title"`
 
 	// Amount is the price of a single unit of the item.
-	Amount float64
+	Amount float64 `json:"amount"`
 
 	// Quantity is the number of units. A value of zero is treated as one.
-	Quantity int
+	Quantity int `json:"quantity"`
+
+	// Discount, if set, reduces this line's subtotal (Amount times Quantity).
+	// Its Kind is ignored.
+	Discount Adjustment `json:"discount"`
+
+	// SKU is the merchant's stock keeping unit or product ID.
+	SKU string `json:"sku,omitempty"`
+
+	// Description is a longer description of the item.
+	Description string `json:"description,omitempty"`
+
+	// Category is the merchant's product category.
+	Category string `json:"category,omitempty"`
+
+	// Metadata holds arbitrary merchant data about the item.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// clone returns a copy of the item that shares no memory with it.
+func (i CartItem) clone() CartItem {
+	if i.Metadata != nil {
+		metadata := make(map[string]string, len(i.Metadata))
+		for k, v := range i.Metadata {
+			metadata[k] = v
+		}
+		i.Metadata = metadata
+	}
+	return i
+}
+
+// cloneItems deep-copies a slice of items, preserving nil.
+func cloneItems(items []CartItem) []CartItem {
+	if items == nil {
+		return nil
+	}
+	out := make([]CartItem, len(items))
+	for i, item := range items {
+		out[i] = item.clone()
+	}
+	return out
 }
 
 // units returns the effective quantity, defaulting to 1 when unset.
@@ -22,37 +65,40 @@ func (i CartItem) units() int {
 	return i.Quantity
 }
 
-// subtotal returns the total cost for this line (amount times quantity).
-func (i CartItem) subtotal() float64 {
-	return i.Amount * float64(i.units())
-}
-
-// cart is the collection of items backing a Payment. It is unexported; callers
-// interact with it through Payment.Add, Payment.Total and Payment.Info.
+// cart is the collection of items and adjustments backing a Payment. It is
+// unexported; callers interact with it through Payment.Add, Payment.Adjust,
+// Payment.Total and Payment.Info.
 type cart struct {
-	items []CartItem
+	items       []CartItem
+	adjustments []Adjustment
 }
 
-// add appends an item to the cart.
+// add appends a copy of an item to the cart.
 func (c *cart) add(item CartItem) {
-	c.items = append(c.items, item)
+	c.items = append(c.items, item.clone())
 }
 
-// total returns the sum of every line's subtotal.
+// adjust appends a cart-level adjustment.
+func (c *cart) adjust(adj Adjustment) {
+	c.adjustments = append(c.adjustments, adj)
+}
+
+// total returns the amount payable, after discounts, taxes and fees.
 func (c *cart) total() float64 {
-	var total float64
-	for _, item := range c.items {
-		total += item.subtotal()
-	}
-	return total
+	return c.breakdown().Total
 }
 
-// summary returns a comma-separated list of item titles, used as the
-// "additionalinfo" field Paynow shows to the customer.
-func (c *cart) summary() string {
-	titles := make([]string, 0, len(c.items))
-	for _, item := range c.items {
-		titles = append(titles, item.Title)
+// summary renders the items with format, or TitleSummary when format is nil,
+// and sanitises the result for use as the "additionalinfo" field Paynow shows
+// to the customer. Titles are cleaned before formatting so stray whitespace
+// does not end up next to separators.
+func (c *cart) summary(format SummaryFormatter) string {
+	if format == nil {
+		format = TitleSummary
+	}
+	items := cloneItems(c.items)
+	for i := range items {
+		items[i].Title = cleanInfo(items[i].Title)
 	}
-	return strings.Join(titles, ", ")
+	return sanitizeInfo(format(items))
 }
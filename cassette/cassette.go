@@ -0,0 +1,77 @@
+// Package cassette records Paynow HTTP interactions to files and replays them,
+// so integration tests can run offline against realistic Paynow responses.
+//
+// Record once against Paynow with a Recorder wrapped around your HTTP client,
+// then replay the cassette in tests with a Replayer:
+//
+//	rec := cassette.NewRecorder(http.DefaultClient, integrationKey)
+//	client := paynow.New(id, integrationKey, paynow.WithHTTPClient(rec))
+//	// ... send and poll ...
+//	err := rec.Cassette().Save("testdata/ecocash.json")
+//
+//	replay, err := cassette.LoadReplayer("testdata/ecocash.json")
+//	client := paynow.New(id, cassette.ReplayKey, paynow.WithHTTPClient(replay))
+//
+// Recorded interactions are scrubbed: the integration key is removed wherever
+// it appears, mobile numbers are replaced with TestPhone, and responses are
+// re-signed with ReplayKey so they verify without the real key.
+package cassette
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// Cassette is a recorded sequence of Paynow HTTP interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a single request to Paynow and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Fields holds the form fields of the body, in
+// order.
+type Request struct {
+	Method string           `json:"method"`
+	URL    string           `json:"url"`
+	Fields signature.Fields `json:"fields,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int    `json:"status_code"`
+	Body       string `json:"body"`
+}
+
+// Load reads a cassette saved with Save.
+func Load(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path as indented JSON, creating its directory
+// if needed.
+func (c *Cassette) Save(path string) error {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
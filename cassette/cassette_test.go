@@ -0,0 +1,147 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+	"github.com/IamTyrone/paynow-go/cassette"
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+const (
+	realKey = "3e9c8b12-real-integration-key"
+	phone   = "0771234567"
+	pollURL = "https://www.paynow.co.zw/interface/poll/1"
+)
+
+// paynowStub plays Paynow for recording: it answers a mobile initiation and
+// then reports the transaction as sent and, on the next poll, paid.
+type paynowStub struct{ polls int }
+
+func (s *paynowStub) Do(req *http.Request) (*http.Response, error) {
+	var fields signature.Fields
+	if strings.Contains(req.URL.Path, "remotetransaction") {
+		fields = signature.Fields{
+			{Key: "status", Value: "Ok"},
+			{Key: "pollurl", Value: pollURL},
+			{Key: "instructions", Value: "Approve the prompt on " + phone},
+		}
+	} else {
+		s.polls++
+		status := "Sent"
+		if s.polls > 1 {
+			status = "Paid"
+		}
+		fields = signature.Fields{
+			{Key: "reference", Value: "INV-1"},
+			{Key: "amount", Value: "10.00"},
+			{Key: "pollurl", Value: pollURL},
+			{Key: "status", Value: status},
+		}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(signature.Append(fields, realKey).Encode())),
+		Header:     make(http.Header),
+	}, nil
+}
+
+func payment() *paynow.Payment {
+	p := paynow.NewPayment("INV-1", "customer@example.com")
+	p.AddItem(paynow.CartItem{Title: "T-shirt", Amount: 10, Quantity: 1})
+	return p
+}
+
+// record runs a mobile payment through client and polls it twice.
+func record(t *testing.T, client *paynow.Client) []paynow.TransactionStatus {
+	t.Helper()
+	ctx := context.Background()
+	init, err := client.SendMobile(ctx, payment(), phone, paynow.MethodEcocash)
+	if err != nil {
+		t.Fatalf("SendMobile() error = %v", err)
+	}
+	var statuses []paynow.TransactionStatus
+	for i := 0; i < 3; i++ {
+		resp, err := client.PollTransaction(ctx, init.PollURL)
+		if err != nil {
+			t.Fatalf("PollTransaction() error = %v", err)
+		}
+		statuses = append(statuses, resp.Status)
+	}
+	return statuses
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	rec := cassette.NewRecorder(&paynowStub{}, realKey)
+	recorded := record(t, paynow.New("12345", realKey, paynow.WithHTTPClient(rec)))
+
+	path := filepath.Join(t.TempDir(), "testdata", "ecocash.json")
+	if err := rec.Cassette().Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{realKey, phone, "12345"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, raw)
+		}
+	}
+
+	replay, err := cassette.LoadReplayer(path)
+	if err != nil {
+		t.Fatalf("LoadReplayer() error = %v", err)
+	}
+	replayed := record(t, paynow.New("67890", cassette.ReplayKey, paynow.WithHTTPClient(replay)))
+
+	want := []paynow.TransactionStatus{"Sent", "Paid", "Paid"}
+	for i := range want {
+		if recorded[i] != want[i] || replayed[i] != want[i] {
+			t.Errorf("poll %d: recorded %q, replayed %q, want %q", i, recorded[i], replayed[i], want[i])
+		}
+	}
+}
+
+func TestRecorder_ScrubsKeyInResponses(t *testing.T) {
+	doer := doerFunc(func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(strings.NewReader("status=Error&error=Invalid+key+" + strings.ToUpper(realKey))),
+		}, nil
+	})
+	rec := cassette.NewRecorder(doer, realKey)
+	_, _ = paynow.New("12345", realKey, paynow.WithHTTPClient(rec)).PollTransaction(context.Background(), pollURL)
+
+	got := rec.Cassette().Interactions
+	if len(got) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(got))
+	}
+	if body := got[0].Response.Body; strings.Contains(strings.ToLower(body), realKey) {
+		t.Errorf("response body = %q, want the key scrubbed", body)
+	}
+	if got[0].Response.StatusCode != http.StatusBadRequest {
+		t.Errorf("StatusCode = %d, want %d", got[0].Response.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestReplayer_NoInteraction(t *testing.T) {
+	replay := cassette.NewReplayer(&cassette.Cassette{})
+	client := paynow.New("12345", cassette.ReplayKey, paynow.WithHTTPClient(replay))
+
+	_, err := client.PollTransaction(context.Background(), pollURL)
+	if !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Errorf("PollTransaction() error = %v, want ErrNoInteraction", err)
+	}
+}
+
+type doerFunc func(*http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
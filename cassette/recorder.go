@@ -0,0 +1,77 @@
+package cassette
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/IamTyrone/paynow-go"
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// Recorder is a paynow.Doer that passes requests through to another Doer and
+// records each request and response, scrubbed, in a Cassette. The caller still
+// receives the real response. It is safe for concurrent use.
+type Recorder struct {
+	next  paynow.Doer
+	scrub scrubber
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder sending requests with next. integrationKey is
+// removed from anything recorded; pass the key the Client uses.
+func NewRecorder(next paynow.Doer, integrationKey string) *Recorder {
+	return &Recorder{next: next, scrub: newScrubber(integrationKey)}
+}
+
+// Do implements paynow.Doer.
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fields, _ := signature.Parse(string(body))
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    r.scrub.value(req.URL.String()),
+			Fields: r.scrub.request(fields),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Body:       r.scrub.response(string(respBody)),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
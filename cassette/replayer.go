@@ -0,0 +1,105 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// ErrNoInteraction is returned by Replayer when no recorded interaction
+// matches a request.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches the request")
+
+// Replayer is a paynow.Doer that answers requests from a Cassette instead of
+// the network. A request matches an interaction with the same method and URL
+// and the same form fields in the same order, ignoring the hash and
+// integration ID, after the same scrubbing applied when recording. Matching
+// interactions are used in recorded order, so repeated polls of one URL replay
+// the statuses seen when recording; the last one repeats once they run out. It
+// is safe for concurrent use.
+type Replayer struct {
+	scrub scrubber
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer returns a Replayer serving c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+// LoadReplayer returns a Replayer serving the cassette saved at path.
+func LoadReplayer(path string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(c), nil
+}
+
+// Do implements paynow.Doer.
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+	fields, err := signature.Parse(string(body))
+	if err != nil {
+		return nil, err
+	}
+	want := Request{
+		Method: req.Method,
+		URL:    r.scrub.value(req.URL.String()),
+		Fields: r.scrub.request(fields),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !matches(interaction.Request, want) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL)
+	}
+	r.used[last] = true
+
+	recorded := r.cassette.Interactions[last].Response
+	return &http.Response{
+		StatusCode: recorded.StatusCode,
+		Status:     fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		Header:     http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:       io.NopCloser(strings.NewReader(recorded.Body)),
+		Request:    req,
+	}, nil
+}
+
+// matches reports whether a recorded request matches want.
+func matches(recorded, want Request) bool {
+	if recorded.Method != want.Method || recorded.URL != want.URL || len(recorded.Fields) != len(want.Fields) {
+		return false
+	}
+	for i, f := range recorded.Fields {
+		if f != want.Fields[i] {
+			return false
+		}
+	}
+	return true
+}
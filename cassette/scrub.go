@@ -0,0 +1,80 @@
+package cassette
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+const (
+	// ReplayKey is the integration key recorded responses are re-signed with.
+	// Use it as the key of a Client replaying a cassette.
+	ReplayKey = "cassette-replay-key"
+
+	// TestPhone replaces every mobile number in a recording.
+	TestPhone = "0770000000"
+
+	// scrubbedValue replaces secrets and the integration ID.
+	scrubbedValue = "SCRUBBED"
+)
+
+// phonePattern matches Zimbabwean mobile numbers in local or international
+// form.
+var phonePattern = regexp.MustCompile(`(?:\+?263|\b0)7\d{8}\b`)
+
+// scrubber removes sensitive data from recorded interactions.
+type scrubber struct {
+	key *regexp.Regexp // the integration key, in any case; nil if unknown
+}
+
+// newScrubber returns a scrubber that also removes integrationKey.
+func newScrubber(integrationKey string) scrubber {
+	if integrationKey == "" {
+		return scrubber{}
+	}
+	return scrubber{key: regexp.MustCompile(`(?i)` + regexp.QuoteMeta(integrationKey))}
+}
+
+// value scrubs a single string.
+func (s scrubber) value(v string) string {
+	if s.key != nil {
+		v = s.key.ReplaceAllString(v, scrubbedValue)
+	}
+	return phonePattern.ReplaceAllString(v, TestPhone)
+}
+
+// request scrubs request fields. The hash is dropped, since it cannot be
+// checked without the key, and the integration ID is replaced so a cassette
+// can be replayed under any ID.
+func (s scrubber) request(fields signature.Fields) signature.Fields {
+	out := make(signature.Fields, 0, len(fields))
+	for _, f := range fields.Signed() {
+		if f.Key == "id" {
+			f.Value = scrubbedValue
+		} else {
+			f.Value = s.value(f.Value)
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// response scrubs a response body, re-signing it with ReplayKey if it was
+// signed.
+func (s scrubber) response(body string) string {
+	fields, err := signature.Parse(body)
+	if err != nil || !strings.Contains(body, "=") {
+		return s.value(body)
+	}
+	_, signed := fields.Hash()
+
+	out := make(signature.Fields, 0, len(fields))
+	for _, f := range fields.Signed() {
+		out = append(out, signature.Field{Key: f.Key, Value: s.value(f.Value)})
+	}
+	if signed {
+		out = signature.Append(out, ReplayKey)
+	}
+	return out.Encode()
+}
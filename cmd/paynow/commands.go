@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/IamTyrone/paynow-go"
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// itemsFlag collects repeated -item flags of the form title:amount[:quantity].
+type itemsFlag []paynow.CartItem
+
+// String implements flag.Value.
+func (f *itemsFlag) String() string {
+	parts := make([]string, len(*f))
+	for i, item := range *f {
+		parts[i] = fmt.Sprintf("%s:%.2f:%d", item.Title, item.Amount, item.Quantity)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Set implements flag.Value.
+func (f *itemsFlag) Set(value string) error {
+	parts := strings.Split(value, ":")
+	item := paynow.CartItem{Quantity: 1}
+
+	if len(parts) >= 3 {
+		if n, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			item.Quantity = n
+			parts = parts[:len(parts)-1]
+		}
+	}
+	if len(parts) < 2 {
+		return fmt.Errorf("item %q: want title:amount[:quantity]", value)
+	}
+	amount, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return fmt.Errorf("item %q: invalid amount", value)
+	}
+	item.Title = strings.Join(parts[:len(parts)-1], ":")
+	item.Amount = amount
+	*f = append(*f, item)
+	return nil
+}
+
+// paymentFlags are the flags describing a payment to initiate.
+type paymentFlags struct {
+	configFlags
+	reference string
+	email     string
+	items     itemsFlag
+}
+
+// register adds the payment flags to fs.
+func (f *paymentFlags) register(fs *flag.FlagSet) {
+	f.configFlags.register(fs)
+	fs.StringVar(&f.reference, "reference", "", "merchant reference (required)")
+	fs.StringVar(&f.email, "email", "", "customer email")
+	fs.Var(&f.items, "item", "item as title:amount[:quantity] (repeatable, required)")
+}
+
+// payment builds the Payment described by the flags.
+func (f *paymentFlags) payment() *paynow.Payment {
+	payment := paynow.NewPayment(f.reference, f.email)
+	for _, item := range f.items {
+		payment.AddItem(item)
+	}
+	return payment
+}
+
+// send implements "paynow send".
+func (c *cli) send(ctx context.Context, args []string) error {
+	var f paymentFlags
+	fs := c.flagSet("send")
+	f.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	client, err := c.client(f.configFlags)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Send(ctx, f.payment())
+	return c.print(resp, err)
+}
+
+// sendMobile implements "paynow send-mobile".
+func (c *cli) sendMobile(ctx context.Context, args []string) error {
+	var (
+		f      paymentFlags
+		phone  string
+		method string
+	)
+	fs := c.flagSet("send-mobile")
+	f.register(fs)
+	fs.StringVar(&phone, "phone", "", "customer mobile number (required)")
+	fs.StringVar(&method, "method", string(paynow.MethodEcocash), "ecocash, onemoney or innbucks")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	client, err := c.client(f.configFlags)
+	if err != nil {
+		return err
+	}
+	resp, err := client.SendMobile(ctx, f.payment(), phone, paynow.PaymentMethod(strings.ToLower(method)))
+	return c.print(resp, err)
+}
+
+// poll implements "paynow poll".
+func (c *cli) poll(ctx context.Context, args []string) error {
+	var f configFlags
+	fs := c.flagSet("poll")
+	f.register(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "usage: paynow poll [flags] <poll URL>")
+		return errUsage
+	}
+
+	client, err := c.client(f)
+	if err != nil {
+		return err
+	}
+	resp, err := client.PollTransaction(ctx, fs.Arg(0))
+	return c.print(resp, err)
+}
+
+// verify implements "paynow verify".
+func (c *cli) verify(_ context.Context, args []string) error {
+	var (
+		f    configFlags
+		file string
+	)
+	fs := c.flagSet("verify")
+	f.register(fs)
+	fs.StringVar(&file, "file", "", "file holding the status update body (default stdin)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	body, err := c.readBody(file)
+	if err != nil {
+		return err
+	}
+	client, err := c.client(f)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ProcessStatusUpdate(body)
+	return c.print(resp, err)
+}
+
+// hash implements "paynow hash".
+func (c *cli) hash(_ context.Context, args []string) error {
+	var (
+		f    configFlags
+		body bool
+	)
+	fs := c.flagSet("hash")
+	f.register(fs)
+	fs.BoolVar(&body, "body", false, "print the encoded body with the hash appended")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	fields := make(signature.Fields, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			fmt.Fprintf(c.stderr, "field %q: want key=value\n", arg)
+			return errUsage
+		}
+		fields = append(fields, signature.Field{Key: key, Value: value})
+	}
+
+	key, err := c.key(f)
+	if err != nil {
+		return err
+	}
+	if body {
+		fmt.Fprintln(c.stdout, signature.Append(fields, key).Encode())
+	} else {
+		fmt.Fprintln(c.stdout, signature.Sign(fields, key))
+	}
+	return nil
+}
+
+// readBody reads a request body from file, or from stdin if file is empty.
+func (c *cli) readBody(file string) (string, error) {
+	if file != "" {
+		raw, err := os.ReadFile(file)
+		return string(raw), err
+	}
+	raw, err := io.ReadAll(c.stdin)
+	return string(raw), err
+}
+
+// print writes resp as indented JSON, even when err is set, since responses
+// carry Paynow's error details. It returns err.
+func (c *cli) print(resp any, err error) error {
+	if resp != nil && !isNilPointer(resp) {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		if encErr := enc.Encode(resp); encErr != nil {
+			return encErr
+		}
+	}
+	return err
+}
+
+// isNilPointer reports whether v is a typed nil response pointer.
+func isNilPointer(v any) bool {
+	switch r := v.(type) {
+	case *paynow.InitResponse:
+		return r == nil
+	case *paynow.StatusResponse:
+		return r == nil
+	}
+	return false
+}
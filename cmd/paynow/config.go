@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+// config holds the integration credentials and URLs used by every command.
+type config struct {
+	IntegrationID  string `json:"integration_id"`
+	IntegrationKey string `json:"integration_key"`
+	ResultURL      string `json:"result_url"`
+	ReturnURL      string `json:"return_url"`
+}
+
+// configFlags are the flags shared by every command that talks to Paynow.
+type configFlags struct {
+	path    string
+	timeout time.Duration
+}
+
+// register adds the shared flags to fs.
+func (f *configFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "config", "", "JSON config file (default $PAYNOW_CONFIG)")
+	fs.DurationVar(&f.timeout, "timeout", 30*time.Second, "HTTP timeout")
+}
+
+// loadConfig reads the config file, if any, and overlays the environment.
+func (c *cli) loadConfig(path string) (config, error) {
+	var cfg config
+	if path == "" {
+		path = c.getenv("PAYNOW_CONFIG")
+	}
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := json.Unmarshal(raw, &cfg); err != nil {
+			return cfg, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+
+	for env, field := range map[string]*string{
+		"PAYNOW_INTEGRATION_ID":  &cfg.IntegrationID,
+		"PAYNOW_INTEGRATION_KEY": &cfg.IntegrationKey,
+		"PAYNOW_RESULT_URL":      &cfg.ResultURL,
+		"PAYNOW_RETURN_URL":      &cfg.ReturnURL,
+	} {
+		if v := c.getenv(env); v != "" {
+			*field = v
+		}
+	}
+	return cfg, nil
+}
+
+// key returns the integration key from the config, or an error if there is
+// none.
+func (c *cli) key(flags configFlags) (string, error) {
+	cfg, err := c.loadConfig(flags.path)
+	if err != nil {
+		return "", err
+	}
+	if cfg.IntegrationKey == "" {
+		return "", errors.New("no integration key: set PAYNOW_INTEGRATION_KEY or integration_key in the config file")
+	}
+	return cfg.IntegrationKey, nil
+}
+
+// client builds a Client from the config, with hash diagnostics enabled so
+// mismatches explain themselves.
+func (c *cli) client(flags configFlags) (*paynow.Client, error) {
+	cfg, err := c.loadConfig(flags.path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.IntegrationID == "" || cfg.IntegrationKey == "" {
+		return nil, errors.New("no credentials: set PAYNOW_INTEGRATION_ID and PAYNOW_INTEGRATION_KEY, or use a config file")
+	}
+
+	var doer paynow.Doer = &http.Client{Timeout: flags.timeout}
+	if c.doer != nil {
+		doer = c.doer
+	}
+	return paynow.New(cfg.IntegrationID, cfg.IntegrationKey,
+		paynow.WithResultURL(cfg.ResultURL),
+		paynow.WithReturnURL(cfg.ReturnURL),
+		paynow.WithHTTPClient(doer),
+		paynow.WithHashDiagnostics(),
+	), nil
+}
@@ -0,0 +1,113 @@
+// Command paynow lets operators initiate and investigate Paynow transactions
+// from the command line.
+//
+// Usage:
+//
+//	paynow send        -reference INV-1 -email a@b.co -item "T-shirt:10.00:2"
+//	paynow send-mobile -reference INV-1 -email a@b.co -item "T-shirt:10.00" -phone 0771234567 -method ecocash
+//	paynow poll        <poll URL>
+//	paynow verify      [-file body.txt]          (reads stdin without -file)
+//	paynow hash        [-body] key=value ...
+//	paynow relay       [-listen localhost:8090] [-dir paynow-callbacks] [-forward URL]
+//	paynow redeliver   -target URL [-dir paynow-callbacks] [name ...]
+//
+// Credentials are read from a JSON config file (-config, or $PAYNOW_CONFIG)
+// and then from the environment, which takes precedence:
+// PAYNOW_INTEGRATION_ID, PAYNOW_INTEGRATION_KEY, PAYNOW_RESULT_URL and
+// PAYNOW_RETURN_URL. Responses are printed as JSON.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+// errUsage reports a command line that could not be parsed. The usage has
+// already been printed.
+var errUsage = errors.New("invalid usage")
+
+// cli runs a single invocation. Its fields are swapped out in tests.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	// doer, if set, replaces the HTTP client used to talk to Paynow.
+	doer paynow.Doer
+}
+
+// commands maps each subcommand to its implementation.
+var commands = map[string]func(c *cli, ctx context.Context, args []string) error{
+	"send":        (*cli).send,
+	"send-mobile": (*cli).sendMobile,
+	"poll":        (*cli).poll,
+	"verify":      (*cli).verify,
+	"hash":        (*cli).hash,
+	"relay":       (*cli).relay,
+	"redeliver":   (*cli).redeliver,
+}
+
+const usage = `usage: paynow <command> [flags]
+
+commands:
+  send         initiate a web payment
+  send-mobile  initiate a mobile (express checkout) payment
+  poll         poll a transaction's status
+  verify       verify a status update body from a file or stdin
+  hash         compute the hash for key=value fields
+  relay        receive, verify and store result URL callbacks locally
+  redeliver    re-send stored callbacks to a local URL
+
+Run "paynow <command> -h" for a command's flags.
+`
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := c.run(ctx, os.Args[1:])
+	stop()
+	switch {
+	case errors.Is(err, errUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "paynow:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches args to a subcommand.
+func (c *cli) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, usage)
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.stderr, "unknown command %q\n\n%s", args[0], usage)
+		return errUsage
+	}
+	return cmd(c, ctx, args[1:])
+}
+
+// flagSet returns a FlagSet for a subcommand that reports errors to stderr.
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("paynow "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+// parse parses args into fs, mapping failures (including -h) to errUsage.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
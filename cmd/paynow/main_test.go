@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+const testKey = "3e9c8b12-integration-key"
+
+// stubDoer answers every request with body and records the last request body.
+type stubDoer struct {
+	body     string
+	received string
+}
+
+func (d *stubDoer) Do(req *http.Request) (*http.Response, error) {
+	raw, _ := io.ReadAll(req.Body)
+	d.received = string(raw)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(d.body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+// newTestCLI returns a cli with credentials in its environment, and its
+// stdout and stderr.
+func newTestCLI(stdin string, doer *stubDoer) (*cli, *bytes.Buffer, *bytes.Buffer) {
+	var stdout, stderr bytes.Buffer
+	env := map[string]string{
+		"PAYNOW_INTEGRATION_ID":  "12345",
+		"PAYNOW_INTEGRATION_KEY": testKey,
+	}
+	c := &cli{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(k string) string { return env[k] },
+	}
+	if doer != nil {
+		c.doer = doer
+	}
+	return c, &stdout, &stderr
+}
+
+func TestHashAndVerify(t *testing.T) {
+	c, stdout, _ := newTestCLI("", nil)
+	if err := c.run(context.Background(), []string{"hash", "-body", "reference=INV-1", "amount=10.00", "status=Paid"}); err != nil {
+		t.Fatalf("hash error = %v", err)
+	}
+	body := strings.TrimSpace(stdout.String())
+
+	fields, _ := signature.Parse(body)
+	if err := signature.Verify(fields, testKey); err != nil {
+		t.Fatalf("hash -body produced an unverifiable body: %v", err)
+	}
+
+	c, stdout, _ = newTestCLI(body, nil)
+	if err := c.run(context.Background(), []string{"verify"}); err != nil {
+		t.Fatalf("verify error = %v", err)
+	}
+	var resp struct{ Reference string }
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil || resp.Reference != "INV-1" {
+		t.Errorf("verify output = %s, want the status response as JSON", stdout)
+	}
+}
+
+func TestVerify_ExplainsMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.txt")
+	body := signature.Append(signature.Fields{{Key: "status", Value: "Paid"}}, "wrong-key").Encode()
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _, _ := newTestCLI("", nil)
+	err := c.run(context.Background(), []string{"verify", "-file", path})
+	if err == nil || !strings.Contains(err.Error(), "likely causes") {
+		t.Errorf("verify error = %v, want a diagnosed mismatch", err)
+	}
+}
+
+func TestSendMobile(t *testing.T) {
+	reply := signature.Append(signature.Fields{
+		{Key: "status", Value: "Ok"},
+		{Key: "pollurl", Value: "https://www.paynow.co.zw/interface/poll/1"},
+		{Key: "instructions", Value: "Dial *151#"},
+	}, testKey).Encode()
+	doer := &stubDoer{body: reply}
+	c, stdout, _ := newTestCLI("", doer)
+
+	err := c.run(context.Background(), []string{"send-mobile",
+		"-reference", "INV-1", "-email", "buyer@example.com",
+		"-item", "T-shirt:10.00:2", "-item", "Ratio 1:2:3.50",
+		"-phone", "0771234567", "-method", "EcoCash",
+	})
+	if err != nil {
+		t.Fatalf("send-mobile error = %v", err)
+	}
+	if !strings.Contains(doer.received, "amount=23.50") || !strings.Contains(doer.received, "method=ecocash") {
+		t.Errorf("request = %s, want amount 23.50 via ecocash", doer.received)
+	}
+	if !strings.Contains(stdout.String(), `"instructions": "Dial *151#"`) {
+		t.Errorf("output = %s, want the init response as JSON", stdout)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paynow.json")
+	if err := os.WriteFile(path, []byte(`{"integration_id":"777","integration_key":"file-key","result_url":"https://example.com/r"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _, _ := newTestCLI("", nil)
+	env := map[string]string{"PAYNOW_CONFIG": path, "PAYNOW_INTEGRATION_KEY": "env-key"}
+	c.getenv = func(k string) string { return env[k] }
+
+	cfg, err := c.loadConfig("")
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	want := config{IntegrationID: "777", IntegrationKey: "env-key", ResultURL: "https://example.com/r"}
+	if cfg != want {
+		t.Errorf("loadConfig() = %+v, want %+v (environment overrides the file)", cfg, want)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{nil, {"refund"}, {"poll"}, {"hash", "novalue"}, {"send", "-item", "bad"}} {
+		c, _, stderr := newTestCLI("", nil)
+		if err := c.run(context.Background(), args); !errors.Is(err, errUsage) {
+			t.Errorf("run(%q) error = %v, want errUsage", args, err)
+		}
+		if stderr.Len() == 0 {
+			t.Errorf("run(%q) printed nothing to stderr", args)
+		}
+	}
+}
+
+func TestMissingCredentials(t *testing.T) {
+	c, _, _ := newTestCLI("", nil)
+	c.getenv = func(string) string { return "" }
+	if err := c.run(context.Background(), []string{"poll", "https://www.paynow.co.zw/interface/poll/1"}); err == nil {
+		t.Error("poll without credentials error = nil")
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+// maxCallbackSize bounds the result-URL bodies the relay accepts.
+const maxCallbackSize = 1 << 20
+
+// unsafeName matches characters not allowed in captured callback file names.
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// callbackDir stores captured callback bodies, one file each, named so they
+// sort in the order they arrived.
+type callbackDir string
+
+// save writes body to a new file and returns its name. An existing file is
+// never overwritten: a numeric suffix is added instead.
+func (d callbackDir) save(body, reference string, at time.Time) (string, error) {
+	if err := os.MkdirAll(string(d), 0o700); err != nil {
+		return "", err
+	}
+	base := at.UTC().Format("20060102T150405.000000000")
+	if reference != "" {
+		base += "-" + unsafeName.ReplaceAllString(reference, "_")
+	}
+
+	for n := 1; ; n++ {
+		name := base + ".txt"
+		if n > 1 {
+			name = fmt.Sprintf("%s_%d.txt", base, n)
+		}
+		f, err := os.OpenFile(filepath.Join(string(d), name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(body)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return name, err
+	}
+}
+
+// list returns the names of the captured callbacks, oldest first.
+func (d callbackDir) list() ([]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".txt") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// load returns the body of the captured callback name.
+func (d callbackDir) load(name string) (string, error) {
+	raw, err := os.ReadFile(filepath.Join(string(d), filepath.Base(name)))
+	return string(raw), err
+}
+
+// relayHandler receives result-URL callbacks, verifies and prints them, stores
+// them and optionally forwards them to a local application.
+type relayHandler struct {
+	client  *paynow.Client
+	dir     callbackDir
+	forward string
+	doer    paynow.Doer
+
+	mu  sync.Mutex // serialises output
+	out io.Writer
+}
+
+// ServeHTTP implements http.Handler.
+func (r *relayHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "result URL callbacks must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	raw, err := io.ReadAll(io.LimitReader(req.Body, maxCallbackSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body := string(raw)
+
+	resp, verr := r.client.ProcessStatusUpdate(body)
+	var reference string
+	if resp != nil {
+		reference = resp.Reference
+	}
+	name, serr := r.dir.save(body, reference, time.Now())
+
+	var report bytes.Buffer
+	fmt.Fprintf(&report, "--- callback %s\n", name)
+	if serr != nil {
+		fmt.Fprintf(&report, "could not store callback: %v\n", serr)
+	}
+	if verr != nil {
+		fmt.Fprintf(&report, "INVALID: %v\n", verr)
+		r.print(report.Bytes())
+		http.Error(w, "invalid status update", http.StatusBadRequest)
+		return
+	}
+	enc := json.NewEncoder(&report)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(resp)
+	r.print(report.Bytes())
+
+	// Forward outside the output lock, so a slow application does not hold
+	// up other callbacks.
+	if r.forward != "" {
+		if status, err := deliver(req.Context(), r.doer, r.forward, body); err != nil {
+			r.print([]byte(fmt.Sprintf("forward to %s failed (%s): %v\n", r.forward, name, err)))
+		} else {
+			r.print([]byte(fmt.Sprintf("forwarded to %s: %s (%s)\n", r.forward, status, name)))
+		}
+	}
+	fmt.Fprintln(w, "OK")
+}
+
+// print writes a complete report to the output, so reports from concurrent
+// callbacks do not interleave.
+func (r *relayHandler) print(report []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, _ = r.out.Write(report)
+}
+
+// deliver posts a captured callback body to target, as Paynow would, and
+// returns the response status.
+func deliver(ctx context.Context, doer paynow.Doer, target, body string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := doer.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Status, nil
+}
+
+// httpDoer returns the cli's Doer, or an *http.Client with timeout.
+func (c *cli) httpDoer(timeout time.Duration) paynow.Doer {
+	if c.doer != nil {
+		return c.doer
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// relay implements "paynow relay".
+func (c *cli) relay(ctx context.Context, args []string) error {
+	var (
+		f       configFlags
+		listen  string
+		path    string
+		dir     string
+		forward string
+	)
+	fs := c.flagSet("relay")
+	f.register(fs)
+	fs.StringVar(&listen, "listen", "localhost:8090", "address to receive callbacks on")
+	fs.StringVar(&path, "path", "/paynow/result", "path callbacks are posted to")
+	fs.StringVar(&dir, "dir", "paynow-callbacks", "directory to store captured callbacks in")
+	fs.StringVar(&forward, "forward", "", "URL of your application's result handler to forward each verified callback to")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	client, err := c.client(f)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, &relayHandler{
+		client:  client,
+		dir:     callbackDir(dir),
+		forward: forward,
+		doer:    c.httpDoer(f.timeout),
+		out:     c.stdout,
+	})
+
+	l, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stderr, "receiving callbacks on http://%s%s, storing them in %s\n", l.Addr(), path, dir)
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// redeliver implements "paynow redeliver".
+func (c *cli) redeliver(ctx context.Context, args []string) error {
+	var (
+		dir     string
+		target  string
+		timeout time.Duration
+	)
+	fs := c.flagSet("redeliver")
+	fs.StringVar(&dir, "dir", "paynow-callbacks", "directory of captured callbacks")
+	fs.StringVar(&target, "target", "", "URL to deliver the callbacks to (required)")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "HTTP timeout")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	if target == "" {
+		fmt.Fprintln(c.stderr, "usage: paynow redeliver -target URL [-dir DIR] [name ...]")
+		return errUsage
+	}
+
+	store := callbackDir(dir)
+	names := fs.Args()
+	if len(names) == 0 {
+		var err error
+		if names, err = store.list(); err != nil {
+			return err
+		}
+	}
+
+	doer := c.httpDoer(timeout)
+	for _, name := range names {
+		body, err := store.load(name)
+		if err != nil {
+			return err
+		}
+		status, err := deliver(ctx, doer, target, body)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(c.stdout, "%s: %s\n", name, status)
+	}
+	return nil
+}
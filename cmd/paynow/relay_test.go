@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// appServer records the bodies POSTed to it.
+type appServer struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (a *appServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	a.mu.Lock()
+	a.bodies = append(a.bodies, string(raw))
+	a.mu.Unlock()
+}
+
+func paidCallback(key string) string {
+	return signature.Append(signature.Fields{
+		{Key: "reference", Value: "INV 1/2"},
+		{Key: "amount", Value: "10.00"},
+		{Key: "status", Value: "Paid"},
+	}, key).Encode()
+}
+
+func TestRelayHandler(t *testing.T) {
+	app := &appServer{}
+	target := httptest.NewServer(app)
+	defer target.Close()
+
+	c, stdout, _ := newTestCLI("", nil)
+	client, err := c.client(configFlags{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := callbackDir(t.TempDir())
+	h := &relayHandler{client: client, dir: dir, forward: target.URL, doer: http.DefaultClient, out: stdout}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/paynow/result", strings.NewReader(paidCallback(testKey))))
+	if rec.Code != http.StatusOK {
+		t.Errorf("valid callback status = %d, want 200", rec.Code)
+	}
+	if len(app.bodies) != 1 || app.bodies[0] != paidCallback(testKey) {
+		t.Errorf("forwarded bodies = %q, want the callback", app.bodies)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/paynow/result", strings.NewReader(paidCallback("wrong-key"))))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("forged callback status = %d, want 400", rec.Code)
+	}
+	if len(app.bodies) != 1 {
+		t.Error("forged callback was forwarded")
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, `"reference": "INV 1/2"`) || !strings.Contains(out, "INVALID:") || !strings.Contains(out, "forwarded to") {
+		t.Errorf("output = %s", out)
+	}
+
+	names, err := dir.list()
+	if err != nil || len(names) != 2 || !strings.HasSuffix(names[0], "-INV_1_2.txt") {
+		t.Errorf("stored callbacks = %v, %v; want both, named by reference", names, err)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	dir := callbackDir(t.TempDir())
+	first, _ := dir.save("status=Paid&n=1", "INV-1", mustTime(t, "2024-01-01T10:00:00Z"))
+	_, _ = dir.save("status=Paid&n=2", "INV-2", mustTime(t, "2024-01-01T11:00:00Z"))
+
+	app := &appServer{}
+	target := httptest.NewServer(app)
+	defer target.Close()
+
+	c, stdout, _ := newTestCLI("", nil)
+	if err := c.run(context.Background(), []string{"redeliver", "-dir", string(dir), "-target", target.URL}); err != nil {
+		t.Fatalf("redeliver error = %v", err)
+	}
+	if len(app.bodies) != 2 || app.bodies[0] != "status=Paid&n=1" || app.bodies[1] != "status=Paid&n=2" {
+		t.Errorf("delivered = %q, want both callbacks in order", app.bodies)
+	}
+	if !strings.Contains(stdout.String(), "200 OK") {
+		t.Errorf("output = %s, want each delivery's status", stdout)
+	}
+
+	app.bodies = nil
+	if err := c.run(context.Background(), []string{"redeliver", "-dir", string(dir), "-target", target.URL, first}); err != nil {
+		t.Fatalf("redeliver %s error = %v", first, err)
+	}
+	if len(app.bodies) != 1 {
+		t.Errorf("delivered %d callbacks, want just %s", len(app.bodies), first)
+	}
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return at
+}
+
+func TestCallbackDir_NeverOverwrites(t *testing.T) {
+	dir := callbackDir(t.TempDir())
+	at := mustTime(t, "2024-01-01T10:00:00Z")
+
+	first, err := dir.save("status=Paid&n=1", "INV-1", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := dir.save("status=Paid&n=2", "INV-1", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatalf("save() returned %q twice", first)
+	}
+
+	names, _ := dir.list()
+	if len(names) != 2 || names[0] != first || names[1] != second {
+		t.Errorf("list() = %v, want %q then %q", names, first, second)
+	}
+	if body, _ := dir.load(first); body != "status=Paid&n=1" {
+		t.Errorf("first callback = %q, want it kept", body)
+	}
+}
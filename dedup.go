@@ -0,0 +1,124 @@
+package paynow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultDedupTTL is how long a processed status update is remembered when
+// WithDedupStore is given no TTL. Paynow stops retrying callbacks well within it.
+const DefaultDedupTTL = 24 * time.Hour
+
+// dedupClaimLease is how long a status update stays claimed while it is being
+// processed. A claim that is neither completed nor released within it, for
+// example because the process crashed, expires so a retry can take over.
+const dedupClaimLease = time.Minute
+
+// DedupStore remembers which status updates have already been processed, so
+// Client.ProcessStatusUpdateOnce can recognise retries and replays. A key is
+// first claimed while its update is processed, then marked done once the
+// update has been recorded. Implementations must be safe for concurrent use;
+// back it with a shared cache such as Redis when several instances receive
+// callbacks.
+type DedupStore interface {
+	// Claim atomically marks key as in progress for lease. It reports true if
+	// the key was newly claimed and false if it has been marked done and has
+	// not expired. It returns ErrUpdateInProgress if another claim on key has
+	// not yet been completed, released or expired.
+	Claim(ctx context.Context, key string, lease time.Duration) (bool, error)
+
+	// Done marks a claimed key as processed for ttl.
+	Done(ctx context.Context, key string, ttl time.Duration) error
+
+	// Release removes a claim, so an update whose processing failed can be
+	// retried.
+	Release(ctx context.Context, key string) error
+}
+
+// WithDedupStore sets the DedupStore used by Client.ProcessStatusUpdateOnce and
+// how long processed updates are remembered. A ttl of zero or less uses
+// DefaultDedupTTL. By default each Client has its own MemoryDedupStore.
+func WithDedupStore(store DedupStore, ttl time.Duration) Option {
+	return func(c *Client) {
+		if store == nil {
+			return
+		}
+		if ttl <= 0 {
+			ttl = DefaultDedupTTL
+		}
+		c.dedup = store
+		c.dedupTTL = ttl
+	}
+}
+
+// dedupSweepInterval is how often a MemoryDedupStore drops expired keys.
+const dedupSweepInterval = time.Minute
+
+// MemoryDedupStore is a DedupStore that keeps claims in memory. Expired claims
+// are swept periodically. Create one with NewMemoryDedupStore.
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	claims    map[string]dedupClaim
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// dedupClaim is a claimed key: in progress until done is set.
+type dedupClaim struct {
+	expiry time.Time
+	done   bool
+}
+
+// NewMemoryDedupStore returns an empty MemoryDedupStore.
+func NewMemoryDedupStore() *MemoryDedupStore {
+	return &MemoryDedupStore{claims: make(map[string]dedupClaim), now: time.Now}
+}
+
+// Claim implements DedupStore.
+func (s *MemoryDedupStore) Claim(_ context.Context, key string, lease time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if claim, ok := s.claims[key]; ok && now.Before(claim.expiry) {
+		if !claim.done {
+			return false, ErrUpdateInProgress
+		}
+		return false, nil
+	}
+	s.claims[key] = dedupClaim{expiry: now.Add(lease)}
+	return true, nil
+}
+
+// Done implements DedupStore.
+func (s *MemoryDedupStore) Done(_ context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims[key] = dedupClaim{expiry: s.now().Add(ttl), done: true}
+	return nil
+}
+
+// Release implements DedupStore.
+func (s *MemoryDedupStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claims, key)
+	return nil
+}
+
+// sweep drops expired claims, at most once per dedupSweepInterval. The caller
+// must hold the lock.
+func (s *MemoryDedupStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for key, claim := range s.claims {
+		if !now.Before(claim.expiry) {
+			delete(s.claims, key)
+		}
+	}
+	s.nextSweep = now.Add(dedupSweepInterval)
+}
@@ -0,0 +1,125 @@
+package paynow
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/IamTyrone/paynow-go/internal/hash"
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// HashMismatchError describes a hash mismatch in detail. It is returned in
+// place of ErrHashMismatch, which it matches with errors.Is, when the Client
+// was created with WithHashDiagnostics. It never contains the integration key.
+type HashMismatchError struct {
+	// Received is the hash the response carried.
+	Received string
+
+	// Expected is the hash computed from the response's fields.
+	Expected string
+
+	// Fields lists the names of the fields that were hashed, in order.
+	Fields []string
+
+	// Causes lists likely explanations, such as fields hashed in a different
+	// order, surrounding whitespace, double URL-encoding or the key's case.
+	Causes []string
+}
+
+// Error implements the error interface.
+func (e *HashMismatchError) Error() string {
+	msg := fmt.Sprintf("%s (fields: %s; received: %s; expected: %s",
+		ErrHashMismatch, strings.Join(e.Fields, ", "), e.Received, e.Expected)
+	if len(e.Causes) > 0 {
+		msg += "; likely causes: " + strings.Join(e.Causes, "; ")
+	}
+	return msg + ")"
+}
+
+// Unwrap lets errors.Is match ErrHashMismatch.
+func (e *HashMismatchError) Unwrap() error {
+	return ErrHashMismatch
+}
+
+// WithHashDiagnostics makes the Client report hash mismatches as a
+// *HashMismatchError with the received and expected hashes, the fields hashed
+// and likely causes. Diagnosing a mismatch hashes the response several more
+// times, so enable it while debugging an integration rather than by default.
+func WithHashDiagnostics() Option {
+	return func(c *Client) { c.hashDiagnostics = true }
+}
+
+// diagnoseMismatch builds a *HashMismatchError for values, whose hash did not
+// verify. If the expected hash cannot be computed, ErrHashMismatch is returned.
+func (c *Client) diagnoseMismatch(ctx context.Context, values *orderedValues) error {
+	signer := c.signVerifier()
+	signed := values.signingValues()
+	expected, err := signer.Sign(ctx, signed)
+	if err != nil {
+		return ErrHashMismatch
+	}
+
+	e := &HashMismatchError{Expected: expected}
+	e.Received, _ = values.get("hash")
+	fields := values.fields.Signed()
+	for _, f := range fields {
+		e.Fields = append(e.Fields, f.Key)
+	}
+
+	matches := func(alt []string) bool {
+		h, err := signer.Sign(ctx, alt)
+		return err == nil && strings.EqualFold(h, e.Received)
+	}
+
+	for _, f := range fields {
+		if f.Value != strings.TrimSpace(f.Value) {
+			e.Causes = append(e.Causes, fmt.Sprintf("field %q has leading or trailing whitespace", f.Key))
+		}
+	}
+	if matches(mapValues(signed, strings.TrimSpace)) {
+		e.Causes = append(e.Causes, "the sender hashed the values with surrounding whitespace removed")
+	}
+	if matches(mapValues(signed, unescapeOrSelf)) {
+		e.Causes = append(e.Causes, "the values were URL-encoded twice")
+	}
+	if matches(sortedValues(fields)) {
+		e.Causes = append(e.Causes, "the sender hashed the fields in alphabetical order instead of the order sent")
+	}
+	if c.signer == nil {
+		if key, err := integrationKeyFrom(ctx, c.keys); err == nil && key != strings.ToLower(key) &&
+			strings.EqualFold(hash.MakeVerbatim(signed, key), e.Received) {
+			e.Causes = append(e.Causes, "the sender did not lower-case the integration key before hashing")
+		}
+	}
+	if len(e.Causes) == 0 {
+		e.Causes = append(e.Causes, "the integration key may be wrong, or the message was altered in transit")
+	}
+	return e
+}
+
+// mapValues returns values with f applied to each.
+func mapValues(values []string, f func(string) string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = f(v)
+	}
+	return out
+}
+
+// unescapeOrSelf URL-decodes v, or returns it unchanged if it is not valid.
+func unescapeOrSelf(v string) string {
+	if u, err := url.QueryUnescape(v); err == nil {
+		return u
+	}
+	return v
+}
+
+// sortedValues returns the values of fields ordered by field name.
+func sortedValues(fields signature.Fields) []string {
+	sorted := append(signature.Fields(nil), fields...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted.Values()
+}
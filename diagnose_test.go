@@ -0,0 +1,108 @@
+package paynow_test
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+// tamperedUpdate sends fields but signs hashed with key, so the two disagree.
+func tamperedUpdate(sent, hashed signature.Fields, key string) string {
+	return append(sent, signature.Field{Key: "hash", Value: signature.Sign(hashed, key)}).Encode()
+}
+
+func diagnose(t *testing.T, client *paynow.Client, body string) *paynow.HashMismatchError {
+	t.Helper()
+	_, err := client.ProcessStatusUpdate(body)
+	var mismatch *paynow.HashMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, paynow.ErrHashMismatch) {
+		t.Fatalf("ProcessStatusUpdate() error = %v, want a *HashMismatchError", err)
+	}
+	return mismatch
+}
+
+func hasCause(e *paynow.HashMismatchError, fragment string) bool {
+	for _, cause := range e.Causes {
+		if strings.Contains(cause, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHashDiagnostics_Causes(t *testing.T) {
+	client := paynow.New("12345", testKey, paynow.WithHashDiagnostics())
+	fields := signature.Fields{
+		{Key: "reference", Value: "INV-1"},
+		{Key: "status", Value: "Paid"},
+		{Key: "pollurl", Value: "https://www.paynow.co.zw/interface/poll/1"},
+	}
+
+	sorted := signature.Fields{fields[2], fields[0], fields[1]}
+	e := diagnose(t, client, tamperedUpdate(fields, sorted, testKey))
+	if !hasCause(e, "alphabetical order") {
+		t.Errorf("Causes = %v, want field order", e.Causes)
+	}
+	if strings.Join(e.Fields, ",") != "reference,status,pollurl" {
+		t.Errorf("Fields = %v, want the order received", e.Fields)
+	}
+	if e.Expected == e.Received || len(e.Expected) != 128 {
+		t.Errorf("Expected = %q, Received = %q", e.Expected, e.Received)
+	}
+
+	padded := signature.Fields{fields[0], {Key: "status", Value: "Paid "}, fields[2]}
+	e = diagnose(t, client, tamperedUpdate(padded, fields, testKey))
+	if !hasCause(e, `"status" has leading or trailing whitespace`) || !hasCause(e, "whitespace removed") {
+		t.Errorf("Causes = %v, want whitespace", e.Causes)
+	}
+
+	encoded := signature.Fields{fields[0], fields[1], {Key: "pollurl", Value: "https%3A%2F%2Fwww.paynow.co.zw%2Finterface%2Fpoll%2F1"}}
+	e = diagnose(t, client, tamperedUpdate(encoded, fields, testKey))
+	if !hasCause(e, "URL-encoded twice") {
+		t.Errorf("Causes = %v, want double encoding", e.Causes)
+	}
+}
+
+func TestHashDiagnostics_KeyCase(t *testing.T) {
+	const key = "3E9C8B12-Integration-KEY"
+	client := paynow.New("12345", key, paynow.WithHashDiagnostics())
+
+	sum := sha512.Sum512([]byte("INV-1Paid" + key))
+	body := signature.Fields{
+		{Key: "reference", Value: "INV-1"},
+		{Key: "status", Value: "Paid"},
+		{Key: "hash", Value: strings.ToUpper(hex.EncodeToString(sum[:]))},
+	}.Encode()
+
+	e := diagnose(t, client, body)
+	if !hasCause(e, "lower-case the integration key") {
+		t.Errorf("Causes = %v, want key case", e.Causes)
+	}
+	if strings.Contains(strings.ToLower(e.Error()), strings.ToLower(key)) {
+		t.Error("Error() leaks the integration key")
+	}
+}
+
+func TestHashDiagnostics_WrongKey(t *testing.T) {
+	client := paynow.New("12345", testKey, paynow.WithHashDiagnostics())
+	e := diagnose(t, client, statusBody(oldKey, "Paid"))
+	if !hasCause(e, "integration key may be wrong") {
+		t.Errorf("Causes = %v, want a wrong key", e.Causes)
+	}
+	if strings.Contains(e.Error(), testKey) || strings.Contains(e.Error(), oldKey) {
+		t.Error("Error() leaks an integration key")
+	}
+}
+
+func TestHashDiagnostics_OffByDefault(t *testing.T) {
+	_, err := paynow.New("12345", testKey).ProcessStatusUpdate(statusBody(oldKey, "Paid"))
+	var mismatch *paynow.HashMismatchError
+	if !errors.Is(err, paynow.ErrHashMismatch) || errors.As(err, &mismatch) {
+		t.Errorf("ProcessStatusUpdate() error = %v, want plain ErrHashMismatch", err)
+	}
+}
@@ -3,6 +3,8 @@ package paynow
 import (
 	"errors"
 	"fmt"
+
+	"github.com/IamTyrone/paynow-go/signature"
 )
 
 // Sentinel errors returned by the SDK. Callers can match against these with
@@ -22,12 +24,33 @@ var (
 	ErrInvalidEmail = errors.New("paynow: a valid auth email is required for mobile transactions")
 
 	// ErrMissingHash is returned when a response from Paynow that should be
-	// hashed does not contain a hash field.
-	ErrMissingHash = errors.New("paynow: response does not contain a hash")
+	// hashed does not contain a hash field. It is the same error as
+	// signature.ErrMissingHash.
+	ErrMissingHash = signature.ErrMissingHash
 
 	// ErrHashMismatch is returned when the hash on a response from Paynow does
-	// not match the hash computed locally, indicating a tampered or corrupt response.
-	ErrHashMismatch = errors.New("paynow: response hash does not match")
+	// not match the hash computed locally, indicating a tampered or corrupt
+	// response. It is the same error as signature.ErrHashMismatch.
+	ErrHashMismatch = signature.ErrHashMismatch
+
+	// ErrReplayedUpdate is returned by Client.ProcessStatusUpdateOnce when the
+	// same status update has already been processed. It is expected when
+	// Paynow retries a callback, and should be acknowledged without acting on it.
+	ErrReplayedUpdate = errors.New("paynow: status update has already been processed")
+
+	// ErrUpdateInProgress is returned by Client.ProcessStatusUpdateOnce when
+	// the same status update is still being processed, for example when Paynow
+	// retries a callback before the first attempt has finished. Unlike
+	// ErrReplayedUpdate it should not be acknowledged, so that Paynow retries
+	// again in case the first attempt fails.
+	ErrUpdateInProgress = errors.New("paynow: status update is already being processed")
+
+	// ErrNoStore is returned by Client.ProcessStatusUpdateOnce when the Client
+	// has no Store to check status updates against.
+	ErrNoStore = errors.New("paynow: a Store is required to verify status updates")
+
+	// ErrMismatch is matched (via errors.Is) by every *MismatchError.
+	ErrMismatch = errors.New("paynow: transaction does not match")
 )
 
 // APIError represents a business error returned by Paynow itself, for example
@@ -41,3 +64,27 @@ type APIError struct {
 func (e *APIError) Error() string {
 	return fmt.Sprintf("paynow: %s", e.Message)
 }
+
+// MismatchError reports that a field of a status update from Paynow does not
+// match the transaction that was originally initiated, for example an amount
+// lower than the one requested.
+type MismatchError struct {
+	// Field is the Paynow field that differs, such as "amount" or "pollurl".
+	Field string
+
+	// Expected is the value recorded when the transaction was initiated.
+	Expected string
+
+	// Actual is the value Paynow reported.
+	Actual string
+}
+
+// Error implements the error interface.
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("paynow: %s mismatch: expected %q, got %q", e.Field, e.Expected, e.Actual)
+}
+
+// Unwrap lets errors.Is match ErrMismatch.
+func (e *MismatchError) Unwrap() error {
+	return ErrMismatch
+}
@@ -0,0 +1,155 @@
+package paynow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// FileStore is a Store backed by an append-only JSON lines file. Every save and
+// status update appends a full snapshot of the transaction as one line; when
+// the file is opened the snapshots are replayed, the last one for each
+// reference winning. Reads are served from memory.
+//
+// A FileStore must not be shared between processes. Create one with
+// OpenFileStore and release it with Close.
+type FileStore struct {
+	mem  *MemoryStore
+	file *os.File
+	enc  *json.Encoder
+}
+
+// OpenFileStore opens (creating if necessary) the JSON lines file at path and
+// loads the transactions it contains. An incomplete last line, left by a crash
+// part-way through an append, is dropped and truncated from the file; an
+// unreadable line anywhere else is reported as corruption.
+func OpenFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("paynow: failed to open store: %w", err)
+	}
+	mem := NewMemoryStore()
+	if err := loadFileStore(f, path, mem); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &FileStore{mem: mem, file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// loadFileStore replays the snapshots in f into mem, repairing an incomplete
+// last line.
+func loadFileStore(f *os.File, path string, mem *MemoryStore) error {
+	reader := bufio.NewReader(f)
+	var offset int64
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("paynow: failed to read store: %w", err)
+		}
+		last := err != nil
+
+		if len(bytes.TrimSpace(raw)) > 0 {
+			var tx Transaction
+			if err := json.Unmarshal(raw, &tx); err != nil {
+				if !last {
+					return fmt.Errorf("paynow: corrupt store %s at line %d: %w", path, line, err)
+				}
+				if err := f.Truncate(offset); err != nil {
+					return fmt.Errorf("paynow: failed to repair store: %w", err)
+				}
+				return nil
+			}
+			mem.put(&tx)
+			if last {
+				// The record is whole but lost its newline; restore it so the
+				// next append starts on a line of its own.
+				if _, err := f.Write([]byte{'\n'}); err != nil {
+					return fmt.Errorf("paynow: failed to repair store: %w", err)
+				}
+			}
+		}
+		offset += int64(len(raw))
+		if last {
+			return nil
+		}
+	}
+}
+
+// Close closes the underlying file.
+func (s *FileStore) Close() error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	return s.file.Close()
+}
+
+// Save implements Store.
+func (s *FileStore) Save(_ context.Context, tx *Transaction) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	stored, err := s.mem.prepareSave(tx)
+	if err != nil {
+		return err
+	}
+	return s.append(stored)
+}
+
+// UpdateStatus implements Store.
+func (s *FileStore) UpdateStatus(_ context.Context, reference string, status TransactionStatus, paynowReference string) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	updated, err := s.mem.prepareUpdate(reference, status, paynowReference)
+	if err != nil {
+		return err
+	}
+	return s.append(updated)
+}
+
+// CompareAndUpdateStatus implements VersionedStore.
+func (s *FileStore) CompareAndUpdateStatus(_ context.Context, reference string, version int64, status TransactionStatus, paynowReference string) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	if err := s.mem.checkVersion(reference, version); err != nil {
+		return err
+	}
+	updated, err := s.mem.prepareUpdate(reference, status, paynowReference)
+	if err != nil {
+		return err
+	}
+	return s.append(updated)
+}
+
+// append writes tx to the file and, once it is durable, to the in-memory index.
+// The caller must hold the write lock.
+func (s *FileStore) append(tx *Transaction) error {
+	if err := s.enc.Encode(tx); err != nil {
+		return fmt.Errorf("paynow: failed to write store: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("paynow: failed to sync store: %w", err)
+	}
+	s.mem.put(tx)
+	return nil
+}
+
+// FindByReference implements Store.
+func (s *FileStore) FindByReference(ctx context.Context, reference string) (*Transaction, error) {
+	return s.mem.FindByReference(ctx, reference)
+}
+
+// FindByPollURL implements Store.
+func (s *FileStore) FindByPollURL(ctx context.Context, pollURL string) (*Transaction, error) {
+	return s.mem.FindByPollURL(ctx, pollURL)
+}
+
+// ListPending implements Store.
+func (s *FileStore) ListPending(ctx context.Context) ([]*Transaction, error) {
+	return s.mem.ListPending(ctx)
+}
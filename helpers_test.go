@@ -1,12 +1,16 @@
 package paynow_test
 
 import (
+	"context"
 	"crypto/sha512"
 	"encoding/hex"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
 )
 
 // mockDoer is a paynow.Doer that captures the outgoing request and returns a
@@ -67,3 +71,44 @@ func signResponse(key string, fields ...field) string {
 	body.WriteString(h)
 	return body.String()
 }
+
+// statusBody returns a status update for INV-1 (10.00, Paynow reference
+// PN-987) with the given status, signed with key.
+func statusBody(key, status string) string {
+	return signResponse(key,
+		field{"reference", "INV-1"},
+		field{"amount", "10.00"},
+		field{"paynowreference", "PN-987"},
+		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
+		field{"status", status},
+	)
+}
+
+// paidStatusBody returns a Paid status update for INV-1 signed with testKey.
+func paidStatusBody() string {
+	return statusBody(testKey, "Paid")
+}
+
+// newSeededStore returns a MemoryStore holding txs.
+func newSeededStore(t *testing.T, txs ...*paynow.Transaction) *paynow.MemoryStore {
+	t.Helper()
+	store := paynow.NewMemoryStore()
+	for _, tx := range txs {
+		if err := store.Save(context.Background(), tx); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	return store
+}
+
+// newStoreClient returns a Client whose Store holds INV-1, sent for amount.
+func newStoreClient(t *testing.T, amount float64) (*paynow.Client, *paynow.MemoryStore) {
+	t.Helper()
+	store := newSeededStore(t, &paynow.Transaction{
+		Reference: "INV-1",
+		PollURL:   "https://www.paynow.co.zw/interface/poll/1",
+		Amount:    amount,
+		Status:    paynow.StatusSent,
+	})
+	return paynow.New("12345", testKey, paynow.WithStore(store)), store
+}
@@ -9,26 +9,111 @@ package hash
 
 import (
 	"crypto/sha512"
-	"encoding/hex"
+	"crypto/subtle"
+	gohash "hash"
 	"strings"
+	"sync"
 )
 
+// upperHex encodes digests in the case Paynow sends.
+const upperHex = "0123456789ABCDEF"
+
+// hasher streams values into a SHA-512 digest through a fixed buffer, so
+// hashing allocates nothing once a hasher has been pooled.
+type hasher struct {
+	h   gohash.Hash
+	buf [512]byte
+	sum [sha512.Size]byte
+}
+
+var hashers = sync.Pool{
+	New: func() any { return &hasher{h: sha512.New()} },
+}
+
+// digest computes the raw SHA-512 digest of values followed by the integration
+// key exactly as given.
+func digest(values []string, integrationKey string) [sha512.Size]byte {
+	hs := hashers.Get().(*hasher)
+	defer hashers.Put(hs)
+
+	hs.h.Reset()
+	for _, v := range values {
+		hs.write(v)
+	}
+	hs.write(integrationKey)
+	hs.h.Sum(hs.sum[:0])
+	return hs.sum
+}
+
+// write feeds s to the digest in buffer-sized chunks.
+func (hs *hasher) write(s string) {
+	for len(s) > 0 {
+		n := copy(hs.buf[:], s)
+		hs.h.Write(hs.buf[:n])
+		s = s[n:]
+	}
+}
+
 // Make computes the Paynow hash for the given ordered values and integration
 // key. Values must be supplied in the same order they are transmitted to (or
 // were received from) Paynow, since ordering is part of the signature.
 func Make(values []string, integrationKey string) string {
-	var b strings.Builder
-	for _, v := range values {
-		b.WriteString(v)
-	}
-	b.WriteString(strings.ToLower(integrationKey))
+	return encode(digest(values, strings.ToLower(integrationKey)))
+}
 
-	sum := sha512.Sum512([]byte(b.String()))
-	return strings.ToUpper(hex.EncodeToString(sum[:]))
+// MakeVerbatim is like Make but does not lower-case the integration key. It
+// exists to diagnose peers that hash with the key as issued.
+func MakeVerbatim(values []string, integrationKey string) string {
+	return encode(digest(values, integrationKey))
+}
+
+// encode renders a digest as uppercase hex.
+func encode(sum [sha512.Size]byte) string {
+	var out [2 * sha512.Size]byte
+	for i, b := range sum {
+		out[2*i] = upperHex[b>>4]
+		out[2*i+1] = upperHex[b&0x0F]
+	}
+	return string(out[:])
 }
 
 // Equal reports whether the hash computed from values and integrationKey
-// matches the provided candidate hash. The comparison is case-insensitive.
+// matches the provided candidate hash. The candidate is hex-decoded, so case
+// does not matter, and the digests are compared in constant time.
 func Equal(candidate string, values []string, integrationKey string) bool {
-	return strings.EqualFold(candidate, Make(values, integrationKey))
+	received, ok := decodeHex(candidate)
+	if !ok {
+		return false
+	}
+	want := digest(values, strings.ToLower(integrationKey))
+	return subtle.ConstantTimeCompare(received[:], want[:]) == 1
+}
+
+// decodeHex decodes a hex-encoded SHA-512 digest in either case.
+func decodeHex(s string) (out [sha512.Size]byte, ok bool) {
+	if len(s) != 2*sha512.Size {
+		return out, false
+	}
+	for i := range out {
+		hi, ok1 := fromHexChar(s[2*i])
+		lo, ok2 := fromHexChar(s[2*i+1])
+		if !ok1 || !ok2 {
+			return out, false
+		}
+		out[i] = hi<<4 | lo
+	}
+	return out, true
+}
+
+// fromHexChar converts a hex character to its value.
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
 }
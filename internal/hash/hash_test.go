@@ -61,3 +61,68 @@ func TestEqual(t *testing.T) {
 		t.Error("Equal() should reject a wrong hash")
 	}
 }
+
+func TestEqual_RejectsMalformedHashes(t *testing.T) {
+	values := []string{"1", "2"}
+	h := hash.Make(values, "key")
+
+	for name, candidate := range map[string]string{
+		"truncated": h[:len(h)-2],
+		"too long":  h + "00",
+		"not hex":   "ZZ" + h[2:],
+		"empty":     "",
+	} {
+		if hash.Equal(candidate, values, "key") {
+			t.Errorf("Equal() accepted a %s hash", name)
+		}
+	}
+}
+
+func TestMake_LongValues(t *testing.T) {
+	values := []string{strings.Repeat("x", 5000), "tail"}
+	if got, want := hash.Make(values, "Key"), reference(values, "Key"); got != want {
+		t.Errorf("Make() = %s, want %s", got, want)
+	}
+}
+
+// webhookValues are the fields of a typical status update.
+var webhookValues = []string{
+	"INV-1001", "21.61", "PN-98765432",
+	"https://www.paynow.co.zw/Interface/CheckPayment/?guid=3cb27f4b-b3ef-4d1f-9178-5e5e62a43995",
+	"Paid",
+}
+
+func BenchmarkMake(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = hash.Make(webhookValues, "3e9c8b12-integration-key")
+	}
+}
+
+func BenchmarkEqual(b *testing.B) {
+	h := hash.Make(webhookValues, "3e9c8b12-integration-key")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = hash.Equal(h, webhookValues, "3e9c8b12-integration-key")
+	}
+}
+
+// BenchmarkEqual_Concatenated is the previous approach, building the payload
+// in memory and comparing hex strings, for comparison with BenchmarkEqual.
+func BenchmarkEqual_Concatenated(b *testing.B) {
+	h := hash.Make(webhookValues, "3e9c8b12-integration-key")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = strings.EqualFold(h, reference(webhookValues, "3e9c8b12-integration-key"))
+	}
+}
+
+func TestMakeVerbatim_KeepsKeyCase(t *testing.T) {
+	values := []string{"a", "b"}
+	sum := sha512.Sum512([]byte("abKEY"))
+	if got, want := hash.MakeVerbatim(values, "KEY"), strings.ToUpper(hex.EncodeToString(sum[:])); got != want {
+		t.Errorf("MakeVerbatim() = %s, want %s", got, want)
+	}
+}
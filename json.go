@@ -0,0 +1,155 @@
+package paynow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// JSONSchemaVersion is the version of the JSON encoding of Payment,
+// InitResponse and StatusResponse. Every encoded value carries it as
+// "schema_version". It only changes when a field is renamed, removed or
+// changes meaning; new optional fields are added without a new version.
+// Documents without a version are read as version 1.
+//
+// Version 1 encodes a Payment as
+//
+//	{
+//	  "schema_version": 1,
+//	  "reference": "INV-1",
+//	  "auth_email": "customer@example.com",
+//	  "result_url": "https://example.com/paynow/result",
+//	  "return_url": "https://example.com/orders/INV-1",
+//	  "items": [{"title": "T-shirt", "amount": 10, "quantity": 2}],
+//	  "adjustments": [{"kind": "tax", "label": "VAT", "percent": 15}]
+//	}
+//
+// and the responses with the snake_case names of their fields, such as
+// "poll_url", "paynow_reference" and "key_index". Statuses are encoded in
+// Paynow's casing ("Paid") and payment methods in lower case ("ecocash").
+const JSONSchemaVersion = 1
+
+// ErrUnsupportedSchemaVersion is returned when decoding JSON written with a
+// newer schema version than JSONSchemaVersion.
+var ErrUnsupportedSchemaVersion = errors.New("paynow: unsupported JSON schema version")
+
+// schemaVersion is embedded in encoded values to carry JSONSchemaVersion.
+type schemaVersion struct {
+	SchemaVersion int `json:"schema_version"`
+}
+
+// check reports an error if v was written with a schema this SDK cannot read.
+func (v schemaVersion) check() error {
+	if v.SchemaVersion > JSONSchemaVersion {
+		return fmt.Errorf("%w %d (this SDK reads up to %d)", ErrUnsupportedSchemaVersion, v.SchemaVersion, JSONSchemaVersion)
+	}
+	return nil
+}
+
+// paymentJSON is the encoded form of a Payment.
+type paymentJSON struct {
+	schemaVersion
+	Reference   string       `json:"reference"`
+	AuthEmail   string       `json:"auth_email,omitempty"`
+	ResultURL   string       `json:"result_url,omitempty"`
+	ReturnURL   string       `json:"return_url,omitempty"`
+	Items       []CartItem   `json:"items"`
+	Adjustments []Adjustment `json:"adjustments,omitempty"`
+}
+
+// MarshalJSON encodes the payment, including its items and adjustments, so it
+// can be queued for a worker to send. Summary is a function and is not
+// encoded; set it again after decoding if you use a custom one.
+func (p Payment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(paymentJSON{
+		schemaVersion: schemaVersion{JSONSchemaVersion},
+		Reference:     p.Reference,
+		AuthEmail:     p.AuthEmail,
+		ResultURL:     p.ResultURL,
+		ReturnURL:     p.ReturnURL,
+		Items:         p.Items(),
+		Adjustments:   p.cart.adjustments,
+	})
+}
+
+// UnmarshalJSON decodes a payment encoded with MarshalJSON, replacing its
+// fields and cart. Summary is left unchanged.
+func (p *Payment) UnmarshalJSON(data []byte) error {
+	var decoded paymentJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if err := decoded.check(); err != nil {
+		return err
+	}
+
+	p.Reference = decoded.Reference
+	p.AuthEmail = decoded.AuthEmail
+	p.ResultURL = decoded.ResultURL
+	p.ReturnURL = decoded.ReturnURL
+	p.cart = cart{}
+	for _, item := range decoded.Items {
+		p.cart.add(item)
+	}
+	for _, adj := range decoded.Adjustments {
+		p.cart.adjust(adj)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the item, leaving out Discount when it is zero.
+func (i CartItem) MarshalJSON() ([]byte, error) {
+	type plain CartItem
+	encoded := struct {
+		plain
+		Discount *Adjustment `json:"discount,omitempty"`
+	}{plain: plain(i)}
+	if i.Discount != (Adjustment{}) {
+		encoded.Discount = &i.Discount
+	}
+	return json.Marshal(encoded)
+}
+
+// MarshalJSON encodes the response with its schema version.
+func (r InitResponse) MarshalJSON() ([]byte, error) {
+	type plain InitResponse
+	return json.Marshal(struct {
+		schemaVersion
+		plain
+	}{schemaVersion{JSONSchemaVersion}, plain(r)})
+}
+
+// UnmarshalJSON decodes a response encoded with MarshalJSON.
+func (r *InitResponse) UnmarshalJSON(data []byte) error {
+	type plain InitResponse
+	decoded := struct {
+		schemaVersion
+		*plain
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	return decoded.check()
+}
+
+// MarshalJSON encodes the response with its schema version.
+func (r StatusResponse) MarshalJSON() ([]byte, error) {
+	type plain StatusResponse
+	return json.Marshal(struct {
+		schemaVersion
+		plain
+	}{schemaVersion{JSONSchemaVersion}, plain(r)})
+}
+
+// UnmarshalJSON decodes a response encoded with MarshalJSON.
+func (r *StatusResponse) UnmarshalJSON(data []byte) error {
+	type plain StatusResponse
+	decoded := struct {
+		schemaVersion
+		*plain
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	return decoded.check()
+}
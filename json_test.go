@@ -0,0 +1,157 @@
+package paynow_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestPayment_JSONRoundTrip(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "buyer@example.com").
+		AddItem(paynow.CartItem{
+			Title: "T-shirt", Amount: 10, Quantity: 2, SKU: "TS-1",
+			Discount: paynow.Adjustment{Amount: 1},
+			Metadata: map[string]string{"size": "M"},
+		}).
+		Add("Mug", 8).
+		AddTax("VAT", 15)
+	p.ResultURL = "https://example.com/paynow/{reference}"
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(raw), `"schema_version":1`) {
+		t.Errorf("JSON = %s, want schema_version 1", raw)
+	}
+
+	var got paynow.Payment
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.Reference != p.Reference || got.AuthEmail != p.AuthEmail || got.ResultURL != p.ResultURL {
+		t.Errorf("decoded payment = %+v, want %+v", got, *p)
+	}
+	if !reflect.DeepEqual(got.Items(), p.Items()) {
+		t.Errorf("Items() = %+v, want %+v", got.Items(), p.Items())
+	}
+	if !reflect.DeepEqual(got.Adjustments(), p.Adjustments()) {
+		t.Errorf("Adjustments() = %+v, want %+v", got.Adjustments(), p.Adjustments())
+	}
+	if got.Total() != p.Total() {
+		t.Errorf("Total() = %.2f, want %.2f", got.Total(), p.Total())
+	}
+}
+
+func TestPayment_MarshalJSONValue(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "buyer@example.com").Add("Mug", 8)
+	p.Summary = paynow.TitleSummary
+
+	raw, err := json.Marshal(*p)
+	if err != nil {
+		t.Fatalf("Marshal() of a Payment value error = %v", err)
+	}
+	if !strings.Contains(string(raw), `"schema_version":1`) || !strings.Contains(string(raw), `"title":"Mug"`) {
+		t.Errorf("JSON = %s, want the versioned encoding with items", raw)
+	}
+	if strings.Contains(string(raw), `"discount"`) {
+		t.Errorf("JSON = %s, want no discount for an item without one", raw)
+	}
+}
+
+func TestPayment_UnmarshalJSONVersions(t *testing.T) {
+	var p paynow.Payment
+	if err := json.Unmarshal([]byte(`{"reference":"INV-1","items":[{"title":"Mug","amount":8,"quantity":1}]}`), &p); err != nil {
+		t.Fatalf("Unmarshal() without a version error = %v", err)
+	}
+	if p.Total() != 8 {
+		t.Errorf("Total() = %.2f, want 8.00", p.Total())
+	}
+
+	err := json.Unmarshal([]byte(`{"schema_version":2,"reference":"INV-1"}`), &p)
+	if !errors.Is(err, paynow.ErrUnsupportedSchemaVersion) {
+		t.Errorf("Unmarshal() of version 2 error = %v, want ErrUnsupportedSchemaVersion", err)
+	}
+}
+
+func TestInitResponse_JSONRoundTrip(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey,
+		field{"status", "Ok"},
+		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
+		field{"authorizationcode", "ABC123"},
+		field{"authorizationexpires", "2026-01-01 00:00:00"},
+	)}
+	resp, err := newTestClient(doer).SendMobile(context.Background(), paidPayment(), "0771234567", paynow.MethodInnbucks)
+	if err != nil {
+		t.Fatalf("SendMobile() error = %v", err)
+	}
+
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"schema_version":1`, `"poll_url":`, `"authorization_code":"ABC123"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("JSON = %s, want it to contain %s", raw, want)
+		}
+	}
+
+	var got paynow.InitResponse
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&got, resp) {
+		t.Errorf("decoded = %+v, want %+v", got, *resp)
+	}
+}
+
+func TestStatusResponse_JSONRoundTrip(t *testing.T) {
+	resp, err := newTestClient(&mockDoer{response: paidStatusBody()}).
+		PollTransaction(context.Background(), "https://www.paynow.co.zw/interface/poll/1")
+	if err != nil {
+		t.Fatalf("PollTransaction() error = %v", err)
+	}
+
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got paynow.StatusResponse
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&got, resp) {
+		t.Errorf("decoded = %+v, want %+v", got, *resp)
+	}
+
+	err = json.Unmarshal([]byte(`{"schema_version":99}`), &got)
+	if !errors.Is(err, paynow.ErrUnsupportedSchemaVersion) {
+		t.Errorf("Unmarshal() of version 99 error = %v, want ErrUnsupportedSchemaVersion", err)
+	}
+}
+
+func TestStatusAndMethod_Text(t *testing.T) {
+	var decoded struct {
+		Status paynow.TransactionStatus `json:"status"`
+		Method paynow.PaymentMethod     `json:"method"`
+	}
+	if err := json.Unmarshal([]byte(`{"status":" PAID ","method":"EcoCash"}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Status != paynow.StatusPaid || decoded.Method != paynow.MethodEcocash {
+		t.Errorf("decoded = %+v, want Paid via ecocash", decoded)
+	}
+
+	raw, err := json.Marshal(map[paynow.TransactionStatus]paynow.PaymentMethod{"awaiting delivery": "OneMoney"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"Awaiting Delivery":"onemoney"}`; string(raw) != want {
+		t.Errorf("Marshal() = %s, want %s", raw, want)
+	}
+}
@@ -0,0 +1,117 @@
+package paynow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoIntegrationKey is returned when a KeyProvider has no integration key to
+// give, for example because its environment variable is unset or its file is
+// empty.
+var ErrNoIntegrationKey = errors.New("paynow: integration key is not available")
+
+// KeyProvider supplies the primary integration key. The Client asks it every
+// time it signs a request or verifies a response, so an implementation backed
+// by a secrets manager can rotate the key without restarting. Implementations
+// must be safe for concurrent use and should cache: they are called on every
+// request.
+type KeyProvider interface {
+	IntegrationKey(ctx context.Context) (string, error)
+}
+
+// WithKeyProvider makes the Client get its primary integration key from p
+// instead of the key passed to New, which may then be empty.
+func WithKeyProvider(p KeyProvider) Option {
+	return func(c *Client) {
+		if p != nil {
+			c.keys = p
+		}
+	}
+}
+
+// StaticKey is a KeyProvider that always returns the same key. New uses it for
+// the key it is given.
+type StaticKey string
+
+// IntegrationKey implements KeyProvider.
+func (k StaticKey) IntegrationKey(context.Context) (string, error) {
+	if k == "" {
+		return "", ErrNoIntegrationKey
+	}
+	return string(k), nil
+}
+
+// EnvKey is a KeyProvider that reads the key from the named environment
+// variable on every call, so changes to the environment take effect
+// immediately.
+type EnvKey string
+
+// IntegrationKey implements KeyProvider.
+func (name EnvKey) IntegrationKey(context.Context) (string, error) {
+	key := strings.TrimSpace(os.Getenv(string(name)))
+	if key == "" {
+		return "", fmt.Errorf("%w: $%s is not set", ErrNoIntegrationKey, string(name))
+	}
+	return key, nil
+}
+
+// FileKey is a KeyProvider that reads the key from a file, such as a mounted
+// Kubernetes secret, ignoring surrounding whitespace. The file is re-read when
+// its modification time or size changes. Create one with NewFileKey.
+type FileKey struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileKey returns a FileKey reading the key from path. The file is first
+// read on use.
+func NewFileKey(path string) *FileKey {
+	return &FileKey{path: path}
+}
+
+// IntegrationKey implements KeyProvider. If the file cannot be read after a
+// key has been loaded, the error is returned rather than the stale key.
+func (f *FileKey) IntegrationKey(context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.key, nil
+	}
+
+	raw, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(raw))
+	if key == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrNoIntegrationKey, f.path)
+	}
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+	return key, nil
+}
+
+// integrationKeyFrom returns the current key from p.
+func integrationKeyFrom(ctx context.Context, p KeyProvider) (string, error) {
+	key, err := p.IntegrationKey(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNoIntegrationKey) {
+			return "", err
+		}
+		return "", fmt.Errorf("paynow: failed to get integration key: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,82 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestEnvKey(t *testing.T) {
+	t.Setenv("PAYNOW_TEST_KEY", " "+testKey+"\n")
+	client := paynow.New("12345", "", paynow.WithKeyProvider(paynow.EnvKey("PAYNOW_TEST_KEY")))
+
+	if _, err := client.ProcessStatusUpdate(statusBody(testKey, "Paid")); err != nil {
+		t.Fatalf("ProcessStatusUpdate() error = %v", err)
+	}
+
+	t.Setenv("PAYNOW_TEST_KEY", "")
+	if _, err := client.ProcessStatusUpdate(statusBody(testKey, "Paid")); !errors.Is(err, paynow.ErrNoIntegrationKey) {
+		t.Errorf("ProcessStatusUpdate() with unset key error = %v, want ErrNoIntegrationKey", err)
+	}
+}
+
+func TestFileKey_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "paynow.key")
+	if err := os.WriteFile(path, []byte(oldKey+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	client := paynow.New("12345", "", paynow.WithKeyProvider(paynow.NewFileKey(path)))
+
+	if _, err := client.ProcessStatusUpdate(statusBody(oldKey, "Paid")); err != nil {
+		t.Fatalf("ProcessStatusUpdate() with first key error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(testKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.ProcessStatusUpdate(statusBody(testKey, "Paid")); err != nil {
+		t.Errorf("ProcessStatusUpdate() after rotation error = %v", err)
+	}
+	if _, err := client.ProcessStatusUpdate(statusBody(oldKey, "Paid")); !errors.Is(err, paynow.ErrHashMismatch) {
+		t.Errorf("ProcessStatusUpdate() with replaced key error = %v, want ErrHashMismatch", err)
+	}
+}
+
+func TestFileKey_Errors(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if _, err := paynow.NewFileKey(filepath.Join(dir, "missing")).IntegrationKey(ctx); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file error = %v, want os.ErrNotExist", err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := paynow.NewFileKey(empty).IntegrationKey(ctx); !errors.Is(err, paynow.ErrNoIntegrationKey) {
+		t.Errorf("empty file error = %v, want ErrNoIntegrationKey", err)
+	}
+}
+
+func TestKeyProvider_SendFailsWithoutKey(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	client := paynow.New("12345", "", paynow.WithHTTPClient(doer))
+
+	if _, err := client.Send(context.Background(), paidPayment()); !errors.Is(err, paynow.ErrNoIntegrationKey) {
+		t.Errorf("Send() error = %v, want ErrNoIntegrationKey", err)
+	}
+	if doer.capturedURL != "" {
+		t.Error("Send() contacted Paynow without a key")
+	}
+}
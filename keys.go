@@ -0,0 +1,112 @@
+package paynow
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WithPreviousKeys lets the Client verify responses and status updates signed
+// with integration keys it has rotated away from, most recent first. Outgoing
+// requests are always signed with the primary key passed to New. Use
+// Client.KeyUsage to see when a previous key is no longer in use and can be
+// removed.
+func WithPreviousKeys(keys ...string) Option {
+	return func(c *Client) {
+		for _, key := range keys {
+			if key != "" {
+				c.previousKeys = append(c.previousKeys, key)
+			}
+		}
+	}
+}
+
+// KeyUsage counts the responses and status updates verified with each of a
+// Client's integration keys since it was created.
+type KeyUsage struct {
+	// Primary counts verifications with the primary key.
+	Primary uint64
+
+	// Previous counts verifications with each key passed to WithPreviousKeys,
+	// in the same order.
+	Previous []uint64
+
+	// LastPreviousUse is when a previous key last verified a response, or the
+	// zero time if none has. Once it is older than the longest time Paynow
+	// may still send old callbacks, the previous keys can be retired.
+	LastPreviousUse time.Time
+}
+
+// keyStats accumulates KeyUsage. It is safe for concurrent use.
+type keyStats struct {
+	mu           sync.Mutex
+	counts       []uint64 // indexed like KeyIndex
+	lastPrevious time.Time
+}
+
+// record counts a verification with the key at index.
+func (s *keyStats) record(index int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.counts) <= index {
+		s.counts = append(s.counts, 0)
+	}
+	s.counts[index]++
+	if index > 0 {
+		s.lastPrevious = time.Now()
+	}
+}
+
+// KeyUsage reports how often each integration key has verified a response.
+func (c *Client) KeyUsage() KeyUsage {
+	s := c.keyStats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := KeyUsage{
+		Previous:        make([]uint64, len(c.previousKeys)),
+		LastPreviousUse: s.lastPrevious,
+	}
+	for i, n := range s.counts {
+		if i == 0 {
+			usage.Primary = n
+		} else if i <= len(usage.Previous) {
+			usage.Previous[i-1] = n
+		}
+	}
+	return usage
+}
+
+// verifyHash checks the hash on values with the Client's Verifier and then
+// each previous key, records which one matched, and returns its KeyIndex.
+func (c *Client) verifyHash(ctx context.Context, values *orderedValues) (int, error) {
+	index, err := c.matchKey(ctx, values)
+	if errors.Is(err, ErrHashMismatch) && c.hashDiagnostics {
+		return 0, c.diagnoseMismatch(ctx, values)
+	}
+	if err != nil {
+		return 0, err
+	}
+	c.keyStats.record(index)
+	return index, nil
+}
+
+// matchKey returns the KeyIndex of the first integration key that verifies the
+// hash on values, without recording it. It returns ErrMissingHash when there is
+// no hash and ErrHashMismatch when no key matches.
+func (c *Client) matchKey(ctx context.Context, values *orderedValues) (int, error) {
+	err := values.verifyHash(ctx, c.signVerifier())
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, ErrHashMismatch) {
+		return 0, err
+	}
+	for i, key := range c.previousKeys {
+		if values.verifyHash(ctx, KeySigner{Keys: StaticKey(key)}) == nil {
+			return i + 1, nil
+		}
+	}
+	return 0, ErrHashMismatch
+}
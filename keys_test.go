@@ -0,0 +1,82 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+const oldKey = "0b5e7d3a-retired-integration-key"
+
+func TestPreviousKeys_VerifyOldSignatures(t *testing.T) {
+	client := paynow.New("12345", testKey, paynow.WithPreviousKeys(oldKey))
+
+	resp, err := client.ProcessStatusUpdate(statusBody(oldKey, "Paid"))
+	if err != nil {
+		t.Fatalf("ProcessStatusUpdate() with previous key error = %v", err)
+	}
+	if resp.KeyIndex != 1 {
+		t.Errorf("KeyIndex = %d, want 1 for the first previous key", resp.KeyIndex)
+	}
+
+	resp, err = client.ProcessStatusUpdate(statusBody(testKey, "Paid"))
+	if err != nil {
+		t.Fatalf("ProcessStatusUpdate() with primary key error = %v", err)
+	}
+	if resp.KeyIndex != 0 {
+		t.Errorf("KeyIndex = %d, want 0 for the primary key", resp.KeyIndex)
+	}
+
+	if _, err := client.ProcessStatusUpdate(statusBody("unknown-key", "Paid")); !errors.Is(err, paynow.ErrHashMismatch) {
+		t.Errorf("ProcessStatusUpdate() with unknown key error = %v, want ErrHashMismatch", err)
+	}
+
+	usage := client.KeyUsage()
+	if usage.Primary != 1 || len(usage.Previous) != 1 || usage.Previous[0] != 1 {
+		t.Errorf("KeyUsage() = %+v, want one use of each key", usage)
+	}
+	if usage.LastPreviousUse.IsZero() {
+		t.Error("LastPreviousUse is zero after a previous key was used")
+	}
+}
+
+func TestPreviousKeys_SignWithPrimary(t *testing.T) {
+	doer := &mockDoer{response: signResponse(oldKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	client := newTestClient(doer).With(paynow.WithPreviousKeys(oldKey))
+
+	resp, err := client.Send(context.Background(), paidPayment())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if resp.KeyIndex != 1 {
+		t.Errorf("KeyIndex = %d, want 1", resp.KeyIndex)
+	}
+
+	// The request must verify with the primary key alone.
+	values, _ := url.ParseQuery(doer.capturedBody)
+	request := signResponse(testKey,
+		field{"resulturl", values.Get("resulturl")},
+		field{"returnurl", values.Get("returnurl")},
+		field{"reference", values.Get("reference")},
+		field{"amount", values.Get("amount")},
+		field{"id", values.Get("id")},
+		field{"additionalinfo", values.Get("additionalinfo")},
+		field{"authemail", values.Get("authemail")},
+		field{"status", values.Get("status")},
+	)
+	want, _ := url.ParseQuery(request)
+	if values.Get("hash") != want.Get("hash") {
+		t.Errorf("request hash = %s, want one signed with the primary key", values.Get("hash"))
+	}
+}
+
+func TestPreviousKeys_UsageStartsAtZero(t *testing.T) {
+	client := paynow.New("12345", testKey, paynow.WithPreviousKeys(oldKey, ""))
+	usage := client.KeyUsage()
+	if usage.Primary != 0 || len(usage.Previous) != 1 || usage.Previous[0] != 0 || !usage.LastPreviousUse.IsZero() {
+		t.Errorf("KeyUsage() = %+v, want no uses of one previous key", usage)
+	}
+}
@@ -0,0 +1,103 @@
+package paynow
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAmountOutOfRange is matched (via errors.Is) by every *LimitError.
+var ErrAmountOutOfRange = errors.New("paynow: amount is outside the limits for this payment method")
+
+// AmountLimit is the range of transaction totals a payment method accepts.
+type AmountLimit struct {
+	// Min is the smallest accepted total. Zero means no minimum.
+	Min float64
+
+	// Max is the largest accepted total. Zero means no maximum.
+	Max float64
+}
+
+// allows reports whether amount is within the limit, compared to the cent.
+func (l AmountLimit) allows(amount float64) bool {
+	cents := toCents(amount)
+	if l.Min > 0 && cents < toCents(l.Min) {
+		return false
+	}
+	if l.Max > 0 && cents > toCents(l.Max) {
+		return false
+	}
+	return true
+}
+
+// DefaultMethodLimits returns the per-method limits a Client uses unless
+// WithMethodLimits is given. They are conservative defaults for personal
+// wallets; the limits that apply to your integration depend on the wallet tier
+// and your Paynow agreement, so override them as needed.
+func DefaultMethodLimits() map[PaymentMethod]AmountLimit {
+	return map[PaymentMethod]AmountLimit{
+		MethodEcocash:  {Min: 0.10, Max: 500.00},
+		MethodOneMoney: {Min: 0.10, Max: 500.00},
+		MethodInnbucks: {Min: 1.00, Max: 1000.00},
+	}
+}
+
+// LimitError is returned by SendMobile when a payment's total is outside the
+// limits configured for the chosen method.
+type LimitError struct {
+	Method PaymentMethod
+	Amount float64
+	Limit  AmountLimit
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	if e.Limit.Min > 0 && toCents(e.Amount) < toCents(e.Limit.Min) {
+		return fmt.Sprintf("paynow: %s is below the %s minimum of %s", formatAmount(e.Amount), e.Method, formatAmount(e.Limit.Min))
+	}
+	return fmt.Sprintf("paynow: %s is above the %s maximum of %s", formatAmount(e.Amount), e.Method, formatAmount(e.Limit.Max))
+}
+
+// Unwrap lets errors.Is match ErrAmountOutOfRange.
+func (e *LimitError) Unwrap() error {
+	return ErrAmountOutOfRange
+}
+
+// WithMethodLimits replaces the Client's per-method limits. Methods without an
+// entry are not limited beyond Payment.Validate.
+func WithMethodLimits(limits map[PaymentMethod]AmountLimit) Option {
+	return func(c *Client) {
+		c.limits = make(map[PaymentMethod]AmountLimit, len(limits))
+		for method, limit := range limits {
+			c.limits[method.normalize()] = limit
+		}
+	}
+}
+
+// CheckMethodLimit returns a *LimitError if amount is outside the limits
+// configured for method, and nil otherwise.
+func (c *Client) CheckMethodLimit(method PaymentMethod, amount float64) error {
+	method = method.normalize()
+	limit, ok := c.limits[method]
+	if !ok || limit.allows(amount) {
+		return nil
+	}
+	return &LimitError{Method: method, Amount: amount, Limit: limit}
+}
+
+// AvailableMethods returns the supported payment methods that can be used for
+// the payment's total, so checkout can hide the others. It returns nil for a
+// nil payment.
+func (c *Client) AvailableMethods(payment *Payment) []PaymentMethod {
+	if payment == nil {
+		return nil
+	}
+	total := payment.Total()
+
+	var methods []PaymentMethod
+	for _, method := range supportedMethods {
+		if c.CheckMethodLimit(method, total) == nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
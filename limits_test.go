@@ -0,0 +1,49 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestClient_AvailableMethods(t *testing.T) {
+	client := paynow.New("12345", testKey, paynow.WithMethodLimits(map[paynow.PaymentMethod]paynow.AmountLimit{
+		paynow.MethodEcocash:  {Max: 100},
+		paynow.MethodInnbucks: {Min: 50},
+	}))
+
+	small := paynow.NewPayment("INV-1", "").Add("Item", 20.00)
+	if got, want := client.AvailableMethods(small), []paynow.PaymentMethod{paynow.MethodEcocash, paynow.MethodOneMoney}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AvailableMethods(20.00) = %v, want %v", got, want)
+	}
+
+	large := paynow.NewPayment("INV-2", "").Add("Item", 150.00)
+	if got, want := client.AvailableMethods(large), []paynow.PaymentMethod{paynow.MethodOneMoney, paynow.MethodInnbucks}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AvailableMethods(150.00) = %v, want %v", got, want)
+	}
+
+	if got := client.AvailableMethods(nil); got != nil {
+		t.Errorf("AvailableMethods(nil) = %v, want nil", got)
+	}
+}
+
+func TestSendMobile_EnforcesMethodLimits(t *testing.T) {
+	doer := &mockDoer{}
+	client := paynow.New("12345", testKey, paynow.WithHTTPClient(doer))
+	p := paynow.NewPayment("INV-1", "buyer@example.com").Add("Fridge", 900.00)
+
+	_, err := client.SendMobile(context.Background(), p, "0771234567", paynow.MethodEcocash)
+	var limitErr *paynow.LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, paynow.ErrAmountOutOfRange) {
+		t.Fatalf("SendMobile() error = %v, want a *LimitError", err)
+	}
+	if limitErr.Method != paynow.MethodEcocash || limitErr.Amount != 900.00 {
+		t.Errorf("LimitError = %+v", limitErr)
+	}
+	if doer.capturedURL != "" {
+		t.Error("a payment over the limit must not be sent to Paynow")
+	}
+}
@@ -0,0 +1,171 @@
+package paynow
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps transactions in memory. It is useful in
+// tests and for short-lived processes; everything is lost when the process
+// exits. The zero value is not usable; create one with NewMemoryStore.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	byRef  map[string]*Transaction
+	byPoll map[string]string
+	now    func() time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		byRef:  make(map[string]*Transaction),
+		byPoll: make(map[string]string),
+		now:    time.Now,
+	}
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ context.Context, tx *Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.prepareSave(tx)
+	if err != nil {
+		return err
+	}
+	s.put(stored)
+	return nil
+}
+
+// prepareSave returns the copy of tx that Save would store, with its
+// timestamps stamped, without storing it. The caller must hold the lock.
+func (s *MemoryStore) prepareSave(tx *Transaction) (*Transaction, error) {
+	if _, ok := s.byRef[tx.Reference]; ok {
+		return nil, ErrDuplicateReference
+	}
+
+	stored := tx.clone()
+	stored.Version = 1
+	now := s.now()
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = now
+	}
+	if stored.UpdatedAt.IsZero() {
+		stored.UpdatedAt = stored.CreatedAt
+	}
+	return stored, nil
+}
+
+// put stores tx and indexes it by poll URL. The caller must hold the write lock.
+func (s *MemoryStore) put(tx *Transaction) {
+	s.byRef[tx.Reference] = tx
+	if tx.PollURL != "" {
+		s.byPoll[tx.PollURL] = tx.Reference
+	}
+}
+
+// UpdateStatus implements Store.
+func (s *MemoryStore) UpdateStatus(_ context.Context, reference string, status TransactionStatus, paynowReference string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated, err := s.prepareUpdate(reference, status, paynowReference)
+	if err != nil {
+		return err
+	}
+	s.put(updated)
+	return nil
+}
+
+// CompareAndUpdateStatus implements VersionedStore.
+func (s *MemoryStore) CompareAndUpdateStatus(_ context.Context, reference string, version int64, status TransactionStatus, paynowReference string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkVersion(reference, version); err != nil {
+		return err
+	}
+	updated, err := s.prepareUpdate(reference, status, paynowReference)
+	if err != nil {
+		return err
+	}
+	s.put(updated)
+	return nil
+}
+
+// checkVersion reports ErrVersionConflict if the stored transaction is not at
+// version. The caller must hold the lock.
+func (s *MemoryStore) checkVersion(reference string, version int64) error {
+	tx, ok := s.byRef[reference]
+	if !ok {
+		return ErrTransactionNotFound
+	}
+	if tx.Version != version {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// prepareUpdate returns the transaction UpdateStatus would store, without
+// storing it. The caller must hold the lock.
+func (s *MemoryStore) prepareUpdate(reference string, status TransactionStatus, paynowReference string) (*Transaction, error) {
+	tx, ok := s.byRef[reference]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+
+	updated := tx.clone()
+	updated.Status = status
+	if paynowReference != "" {
+		updated.PaynowReference = paynowReference
+	}
+	updated.UpdatedAt = s.now()
+	updated.Version++
+	return updated, nil
+}
+
+// FindByReference implements Store.
+func (s *MemoryStore) FindByReference(_ context.Context, reference string) (*Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tx, ok := s.byRef[reference]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return tx.clone(), nil
+}
+
+// FindByPollURL implements Store.
+func (s *MemoryStore) FindByPollURL(ctx context.Context, pollURL string) (*Transaction, error) {
+	s.mu.RLock()
+	reference, ok := s.byPoll[pollURL]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+	return s.FindByReference(ctx, reference)
+}
+
+// ListPending implements Store.
+func (s *MemoryStore) ListPending(_ context.Context) ([]*Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var pending []*Transaction
+	for _, tx := range s.byRef {
+		if tx.Status.IsPending() {
+			pending = append(pending, tx.clone())
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].CreatedAt.Equal(pending[j].CreatedAt) {
+			return pending[i].Reference < pending[j].Reference
+		}
+		return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+	})
+	return pending, nil
+}
@@ -1,5 +1,7 @@
 package paynow
 
+import "strings"
+
 // PaymentMethod identifies the mobile money method used for an express-checkout
 // (mobile) transaction. Pass one of these to Client.SendMobile.
 type PaymentMethod string
@@ -20,3 +22,34 @@ const (
 func (m PaymentMethod) String() string {
 	return string(m)
 }
+
+// MarshalText implements encoding.TextMarshaler, writing the method in lower
+// case as Paynow expects it.
+func (m PaymentMethod) MarshalText() ([]byte, error) {
+	return []byte(m.normalize()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Case and surrounding
+// whitespace are ignored, so "EcoCash" decodes as MethodEcocash.
+func (m *PaymentMethod) UnmarshalText(text []byte) error {
+	*m = PaymentMethod(text).normalize()
+	return nil
+}
+
+// normalize returns m trimmed and in lower case.
+func (m PaymentMethod) normalize() PaymentMethod {
+	return PaymentMethod(strings.ToLower(strings.TrimSpace(string(m))))
+}
+
+// supportedMethods lists every method the SDK supports, in display order.
+var supportedMethods = []PaymentMethod{MethodEcocash, MethodOneMoney, MethodInnbucks}
+
+// known reports whether m is one of the methods the SDK supports.
+func (m PaymentMethod) known() bool {
+	for _, supported := range supportedMethods {
+		if m == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+-- Creates the transactions table. Amounts are stored in cents and timestamps
+-- as Unix nanoseconds so no driver-specific type conversion is needed.
+CREATE TABLE paynow_transactions (
+    reference        TEXT    NOT NULL PRIMARY KEY,
+    paynow_reference TEXT    NOT NULL DEFAULT '',
+    poll_url         TEXT    NOT NULL DEFAULT '',
+    amount_cents     INTEGER NOT NULL,
+    method           TEXT    NOT NULL DEFAULT '',
+    status           TEXT    NOT NULL,
+    pending          INTEGER NOT NULL,
+    version          INTEGER NOT NULL,
+    created_at       INTEGER NOT NULL,
+    updated_at       INTEGER NOT NULL
+);
+
+CREATE INDEX paynow_transactions_poll_url ON paynow_transactions (poll_url);
+
+-- Partial index backing ListPending, which only ever scans pending rows.
+CREATE INDEX paynow_transactions_pending ON paynow_transactions (created_at, reference) WHERE pending = 1;
@@ -0,0 +1,2 @@
+-- Stores the cart items sent with each transaction, as a JSON array.
+ALTER TABLE paynow_transactions ADD COLUMN items TEXT NOT NULL DEFAULT '';
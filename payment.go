@@ -5,7 +5,8 @@ package paynow
 // Client.Send or Client.SendMobile.
 //
 // A Payment behaves like a shopping cart: its total is the sum of the items
-// added to it, and the item titles are sent to Paynow as additional info.
+// added to it, adjusted by any discounts, taxes and fees, and the item titles
+// are sent to Paynow as additional info.
 type Payment struct {
 	// Reference is the merchant's unique identifier for the transaction.
 	Reference string
@@ -14,6 +15,16 @@ type Payment struct {
 	// (express checkout) transactions and optional for web transactions.
 	AuthEmail string
 
+	// Summary formats the items into the additional info Paynow shows the
+	// customer. When nil, TitleSummary is used. It is not encoded as JSON.
+	Summary SummaryFormatter `json:"-"`
+
+	// ResultURL and ReturnURL, when set, override the Client's result and
+	// return URLs for this payment only, for example to route each tenant of a
+	// multi-tenant service to its own endpoints. See ReferencePlaceholder.
+	ResultURL string
+	ReturnURL string
+
 	cart cart
 }
 
@@ -41,19 +52,75 @@ func (p *Payment) Add(title string, amount float64, quantity ...int) *Payment {
 	return p
 }
 
-// Items returns a copy of the items currently in the payment's cart.
+// AddItem appends a fully specified item, for example one with a line
+// discount, and returns the payment so calls can be chained.
+func (p *Payment) AddItem(item CartItem) *Payment {
+	p.cart.add(item)
+	return p
+}
+
+// Adjust applies a cart-level discount, tax or fee and returns the payment so
+// calls can be chained. See Breakdown for the order adjustments are applied in.
+func (p *Payment) Adjust(adj Adjustment) *Payment {
+	p.cart.adjust(adj)
+	return p
+}
+
+// AddDiscount applies a fixed discount to the whole cart.
+func (p *Payment) AddDiscount(label string, amount float64) *Payment {
+	return p.Adjust(Adjustment{Kind: AdjustmentDiscount, Label: label, Amount: amount})
+}
+
+// AddPercentDiscount applies a percentage discount (10 for 10%) to the whole
+// cart.
+func (p *Payment) AddPercentDiscount(label string, percent float64) *Payment {
+	return p.Adjust(Adjustment{Kind: AdjustmentDiscount, Label: label, Percent: percent})
+}
+
+// AddTax charges a percentage tax such as VAT (15 for 15%) on the discounted
+// subtotal. For prices that already include tax, use Adjust with Inclusive set.
+func (p *Payment) AddTax(label string, percent float64) *Payment {
+	return p.Adjust(Adjustment{Kind: AdjustmentTax, Label: label, Percent: percent})
+}
+
+// AddFee charges a fixed merchant fee or surcharge.
+func (p *Payment) AddFee(label string, amount float64) *Payment {
+	return p.Adjust(Adjustment{Kind: AdjustmentFee, Label: label, Amount: amount})
+}
+
+// Items returns a copy of the items currently in the payment's cart, including
+// their local SKU, description, category and metadata.
 func (p *Payment) Items() []CartItem {
-	items := make([]CartItem, len(p.cart.items))
-	copy(items, p.cart.items)
+	items := cloneItems(p.cart.items)
+	if items == nil {
+		items = []CartItem{}
+	}
 	return items
 }
 
-// Total returns the combined cost of every item in the cart.
+// Adjustments returns a copy of the cart-level adjustments, in the order they
+// were applied.
+func (p *Payment) Adjustments() []Adjustment {
+	adjustments := make([]Adjustment, len(p.cart.adjustments))
+	copy(adjustments, p.cart.adjustments)
+	return adjustments
+}
+
+// Total returns the amount payable: the combined cost of every item, after
+// discounts, taxes and fees, rounded to the cent. This is the amount sent to
+// Paynow.
 func (p *Payment) Total() float64 {
 	return p.cart.total()
 }
 
-// Info returns a comma-separated summary of the item titles in the cart.
+// Breakdown itemises how Total is made up, for rendering receipts.
+func (p *Payment) Breakdown() Breakdown {
+	return p.cart.breakdown()
+}
+
+// Info returns the additional info sent to Paynow: the items rendered by
+// Summary (a comma-separated list of titles by default), with control and
+// markup characters removed and limited to MaxInfoLength characters.
 func (p *Payment) Info() string {
-	return p.cart.summary()
+	return p.cart.summary(p.Summary)
 }
@@ -1,6 +1,7 @@
 package paynow_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/IamTyrone/paynow-go"
@@ -47,3 +48,39 @@ func TestClient_CreatePayment(t *testing.T) {
 		t.Errorf("CreatePayment() = %+v, want reference/authEmail set", p)
 	}
 }
+
+func TestPayment_ItemMetadata(t *testing.T) {
+	p := paynow.NewPayment("INV-5", "buyer@example.com").AddItem(paynow.CartItem{
+		Title:       "Mug",
+		Amount:      8.00,
+		SKU:         "MUG-RED",
+		Description: "Red ceramic mug",
+		Category:    "Kitchen",
+		Metadata:    map[string]string{"product_id": "981"},
+	})
+
+	items := p.Items()
+	if items[0].SKU != "MUG-RED" || items[0].Category != "Kitchen" || items[0].Metadata["product_id"] != "981" {
+		t.Fatalf("Items() = %+v, want local fields preserved", items[0])
+	}
+
+	items[0].Metadata["product_id"] = "changed"
+	if p.Items()[0].Metadata["product_id"] != "981" {
+		t.Error("mutating metadata from Items() should not affect the payment")
+	}
+	if p.Info() != "Mug" {
+		t.Errorf("Info() = %q, local fields must not be sent to Paynow", p.Info())
+	}
+
+	raw, err := json.Marshal(items[0])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded paynow.CartItem
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.SKU != "MUG-RED" || decoded.Description != "Red ceramic mug" || decoded.Metadata["product_id"] != "changed" {
+		t.Errorf("round-tripped item = %+v", decoded)
+	}
+}
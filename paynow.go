@@ -30,7 +30,11 @@
 //	fmt.Println(resp.PollURL)
 package paynow
 
-import "net/http"
+import (
+	"net/http"
+	"sync"
+	"time"
+)
 
 // Doer is the subset of *http.Client the SDK needs. It lets callers inject a
 // custom client (for timeouts, proxies, tracing or testing). *http.Client
@@ -40,14 +44,30 @@ type Doer interface {
 }
 
 // Client talks to the Paynow API on behalf of a single merchant integration.
-// Create one with New. A Client is safe for concurrent use as long as the
-// injected Doer is.
+// Create one with New, and derive differently configured clients from it with
+// With. A Client is safe for concurrent use as long as the injected Doer, Store
+// and DedupStore are; its configuration does not change after New, apart from
+// the deprecated SetResultURL and SetReturnURL.
 type Client struct {
-	integrationID  string
-	integrationKey string
-	resultURL      string
-	returnURL      string
-	httpClient     Doer
+	integrationID string
+	keys          KeyProvider
+	signer        SignVerifier
+	previousKeys  []string
+	keyStats      *keyStats
+
+	mu        sync.RWMutex // guards resultURL and returnURL
+	resultURL string
+	returnURL string
+
+	httpClient      Doer
+	store           Store
+	statuses        *StatusMachine
+	limits          map[PaymentMethod]AmountLimit
+	verifyStatus    bool
+	currency        string
+	hashDiagnostics bool
+	dedup           DedupStore
+	dedupTTL        time.Duration
 }
 
 // Option configures a Client. Pass options to New.
@@ -75,13 +95,19 @@ func WithHTTPClient(doer Doer) Option {
 }
 
 // New creates a Client for the given integration credentials. Result and return
-// URLs are optional here and can be supplied with WithResultURL / WithReturnURL
-// or later with SetResultURL / SetReturnURL.
+// URLs are optional here and can be supplied with WithResultURL / WithReturnURL,
+// or per payment with Payment.ResultURL / Payment.ReturnURL. To keep the
+// integration key out of configuration, pass an empty key and WithKeyProvider.
 func New(integrationID, integrationKey string, opts ...Option) *Client {
 	c := &Client{
-		integrationID:  integrationID,
-		integrationKey: integrationKey,
-		httpClient:     &http.Client{},
+		integrationID: integrationID,
+		keys:          StaticKey(integrationKey),
+		keyStats:      &keyStats{},
+		httpClient:    &http.Client{},
+		statuses:      NewStatusMachine(),
+		limits:        DefaultMethodLimits(),
+		dedup:         NewMemoryDedupStore(),
+		dedupTTL:      DefaultDedupTTL,
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -89,8 +115,69 @@ func New(integrationID, integrationKey string, opts ...Option) *Client {
 	return c
 }
 
+// With returns a new Client with the same credentials and configuration as c,
+// with opts applied on top. c is not modified. The derived Client shares c's
+// Doer, Store, StatusMachine and DedupStore unless opts replace them, and
+// starts with its own KeyUsage.
+func (c *Client) With(opts ...Option) *Client {
+	resultURL, returnURL := c.urls()
+	d := &Client{
+		integrationID:   c.integrationID,
+		keys:            c.keys,
+		signer:          c.signer,
+		previousKeys:    append([]string(nil), c.previousKeys...),
+		keyStats:        &keyStats{},
+		resultURL:       resultURL,
+		returnURL:       returnURL,
+		httpClient:      c.httpClient,
+		store:           c.store,
+		statuses:        c.statuses,
+		limits:          make(map[PaymentMethod]AmountLimit, len(c.limits)),
+		verifyStatus:    c.verifyStatus,
+		currency:        c.currency,
+		hashDiagnostics: c.hashDiagnostics,
+		dedup:           c.dedup,
+		dedupTTL:        c.dedupTTL,
+	}
+	for method, limit := range c.limits {
+		d.limits[method] = limit
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Clone returns a copy of c. It is equivalent to c.With().
+func (c *Client) Clone() *Client {
+	return c.With()
+}
+
 // SetResultURL sets the URL Paynow posts transaction status updates to.
-func (c *Client) SetResultURL(url string) { c.resultURL = url }
+//
+// Deprecated: Changing a shared Client's URLs affects every payment sent
+// through it. Use With(WithResultURL(url)) to derive a Client, or set
+// Payment.ResultURL.
+func (c *Client) SetResultURL(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resultURL = url
+}
 
 // SetReturnURL sets the URL the customer is returned to after paying.
-func (c *Client) SetReturnURL(url string) { c.returnURL = url }
+//
+// Deprecated: Changing a shared Client's URLs affects every payment sent
+// through it. Use With(WithReturnURL(url)) to derive a Client, or set
+// Payment.ReturnURL.
+func (c *Client) SetReturnURL(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.returnURL = url
+}
+
+// urls returns the Client's result and return URLs.
+func (c *Client) urls() (resultURL, returnURL string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.resultURL, c.returnURL
+}
@@ -0,0 +1,125 @@
+package paynow_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+// routeDoer is a paynow.Doer, safe for concurrent use, that answers each
+// request with the response for the first path fragment its URL contains.
+type routeDoer map[string]string
+
+func (d routeDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+	}
+	for fragment, response := range d {
+		if strings.Contains(req.URL.String(), fragment) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(response)),
+				Header:     make(http.Header),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected request to %s", req.URL)
+}
+
+func TestClient_ConcurrentUse(t *testing.T) {
+	initiated := signResponse(testKey,
+		field{"status", "Ok"},
+		field{"browserurl", "https://www.paynow.co.zw/payment/confirm/1"},
+		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
+	)
+	client := newTestClient(routeDoer{
+		"initiatetransaction": initiated,
+		"remotetransaction":   initiated,
+		"interface/poll":      paidStatusBody(),
+	})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 16; i++ {
+		i := i
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			payment := paynow.NewPayment(fmt.Sprintf("WEB-%d", i), "buyer@example.com").Add("Item", 10.00)
+			_, err := client.Send(ctx, payment)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			payment := paynow.NewPayment(fmt.Sprintf("MOB-%d", i), "buyer@example.com").Add("Item", 10.00)
+			_, err := client.SendMobile(ctx, payment, "0771234567", paynow.MethodEcocash)
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := client.PollTransaction(ctx, "https://www.paynow.co.zw/interface/poll/1")
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			//lint:ignore SA1019 the deprecated setter must stay race-free
+			client.SetReturnURL(fmt.Sprintf("https://example.com/return/%d", i))
+			_ = client.With(paynow.WithResultURL("https://tenant.example.com/result"))
+			errs <- nil
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent call error = %v", err)
+		}
+	}
+}
+
+func TestClient_With(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	base := newTestClient(doer)
+	tenant := base.With(paynow.WithResultURL("https://tenant.example.com/result"))
+
+	_, _ = tenant.Send(context.Background(), paidPayment())
+	values, _ := url.ParseQuery(doer.capturedBody)
+	if got := values.Get("resulturl"); got != "https://tenant.example.com/result" {
+		t.Errorf("derived client resulturl = %q, want the overridden URL", got)
+	}
+	if got := values.Get("returnurl"); got != "https://example.com/return" {
+		t.Errorf("derived client returnurl = %q, want the inherited URL", got)
+	}
+	if got := values.Get("id"); got != "12345" {
+		t.Errorf("derived client id = %q, want 12345", got)
+	}
+
+	_, _ = base.Send(context.Background(), paidPayment())
+	values, _ = url.ParseQuery(doer.capturedBody)
+	if got := values.Get("resulturl"); got != "https://example.com/result" {
+		t.Errorf("base client resulturl = %q, want it unchanged by With", got)
+	}
+}
+
+func TestClient_WithCopiesLimits(t *testing.T) {
+	base := paynow.New("12345", testKey)
+	strict := base.With(paynow.WithMethodLimits(map[paynow.PaymentMethod]paynow.AmountLimit{
+		paynow.MethodEcocash: {Min: 1, Max: 5},
+	}))
+
+	if err := strict.CheckMethodLimit(paynow.MethodEcocash, 10); err == nil {
+		t.Error("derived client accepted an amount above its limit")
+	}
+	if err := base.CheckMethodLimit(paynow.MethodEcocash, 10); err != nil {
+		t.Errorf("base client CheckMethodLimit() error = %v, want its own limits", err)
+	}
+}
@@ -4,7 +4,10 @@ import "context"
 
 // PollTransaction checks the current status of a transaction using the poll URL
 // returned when the transaction was initiated. The response hash is verified for
-// non-error responses.
+// non-error responses. When the Client has a Store, the observed status is
+// recorded there; a failure to record it is returned with the response. With
+// WithStatusVerification the response is first checked against the stored
+// transaction.
 func (c *Client) PollTransaction(ctx context.Context, pollURL string) (*StatusResponse, error) {
 	raw, err := c.postForm(ctx, pollURL, "")
 	if err != nil {
@@ -22,17 +25,43 @@ func (c *Client) PollTransaction(ctx context.Context, pollURL string) (*StatusRe
 		return resp, &APIError{Message: resp.Error}
 	}
 
-	if err := values.verifyHash(c.integrationKey); err != nil {
+	keyIndex, err := c.verifyHash(ctx, values)
+	if err != nil {
 		return nil, err
 	}
-	return newStatusResponse(values), nil
+	resp := newStatusResponse(values)
+	resp.KeyIndex = keyIndex
+	if c.verifyStatus {
+		if err := c.verifyStored(ctx, pollURL, resp); err != nil {
+			return resp, err
+		}
+	}
+	return resp, c.recordStatus(ctx, pollURL, resp)
 }
 
 // ProcessStatusUpdate parses and verifies a status update that Paynow posts to
 // your result URL. Pass the raw request body (for example the bytes read from
 // http.Request.Body) so the hash can be verified against the exact field order
-// Paynow used.
+// Paynow used. Like PollTransaction, the status is recorded in the Client's
+// Store when one is configured.
 func (c *Client) ProcessStatusUpdate(rawBody string) (*StatusResponse, error) {
+	ctx := context.Background()
+	resp, err := c.parseStatusUpdate(ctx, rawBody)
+	if err != nil {
+		return resp, err
+	}
+
+	if c.verifyStatus {
+		if err := c.verifyStored(ctx, "", resp); err != nil {
+			return resp, err
+		}
+	}
+	return resp, c.recordStatus(ctx, "", resp)
+}
+
+// parseStatusUpdate parses a result-URL body and verifies its hash. Error
+// statuses are returned as a populated response with an *APIError.
+func (c *Client) parseStatusUpdate(ctx context.Context, rawBody string) (*StatusResponse, error) {
 	values, err := parseResponse(rawBody)
 	if err != nil {
 		return nil, err
@@ -44,8 +73,11 @@ func (c *Client) ProcessStatusUpdate(rawBody string) (*StatusResponse, error) {
 		return resp, &APIError{Message: resp.Error}
 	}
 
-	if err := values.verifyHash(c.integrationKey); err != nil {
+	keyIndex, err := c.verifyHash(ctx, values)
+	if err != nil {
 		return nil, err
 	}
-	return newStatusResponse(values), nil
+	resp := newStatusResponse(values)
+	resp.KeyIndex = keyIndex
+	return resp, nil
 }
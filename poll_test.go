@@ -8,16 +8,6 @@ import (
 	"github.com/IamTyrone/paynow-go"
 )
 
-func paidStatusBody() string {
-	return signResponse(testKey,
-		field{"reference", "INV-1"},
-		field{"amount", "10.00"},
-		field{"paynowreference", "PN-987"},
-		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
-		field{"status", "Paid"},
-	)
-}
-
 func TestPollTransaction_Success(t *testing.T) {
 	doer := &mockDoer{response: paidStatusBody()}
 
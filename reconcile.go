@@ -0,0 +1,212 @@
+package paynow
+
+import (
+	"context"
+	"sync"
+)
+
+// UpdateSource identifies where an observed status came from.
+type UpdateSource string
+
+const (
+	// SourcePoll marks a status obtained with Client.PollTransaction.
+	SourcePoll UpdateSource = "poll"
+
+	// SourceWebhook marks a status Paynow posted to the result URL.
+	SourceWebhook UpdateSource = "webhook"
+)
+
+// StatusEvent is emitted by a Reconciler when a transaction's status really
+// changes.
+type StatusEvent struct {
+	// Reference is the merchant's reference, or Paynow's reference when the
+	// update did not carry one.
+	Reference string
+
+	// Transition is the change of status. From is empty for the first status
+	// observed for a transaction.
+	Transition StatusTransition
+
+	// Source is where the status that caused the change came from.
+	Source UpdateSource
+
+	// Response is the status response that caused the change.
+	Response *StatusResponse
+}
+
+// Reconciler merges status updates from polls and webhooks into a single,
+// ordered stream with exactly one StatusEvent per real state change.
+// Duplicates (the same hash seen twice, or a status equal to the current one)
+// and stale updates the StatusMachine rejects are dropped.
+//
+// Subscribers are called synchronously, in order, while the Reconciler holds
+// its lock, so they must not call back into the Reconciler. A Reconciler keeps
+// the last status of every transaction it has seen; call Forget once a
+// transaction is settled. It is safe for concurrent use.
+type Reconciler struct {
+	client   *Client
+	statuses *StatusMachine
+
+	mu          sync.Mutex
+	states      map[string]reconciledState
+	aliases     map[string]string
+	subscribers map[int]func(StatusEvent)
+	nextID      int
+}
+
+// reconciledState is what a Reconciler remembers about one transaction.
+type reconciledState struct {
+	status TransactionStatus
+	hashes map[string]bool
+}
+
+// NewReconciler returns a Reconciler that polls and verifies webhooks with
+// client, and orders updates with the client's StatusMachine.
+func NewReconciler(client *Client) *Reconciler {
+	return &Reconciler{
+		client:      client,
+		statuses:    client.statuses,
+		states:      make(map[string]reconciledState),
+		aliases:     make(map[string]string),
+		subscribers: make(map[int]func(StatusEvent)),
+	}
+}
+
+// Subscribe registers fn to receive every StatusEvent. It returns a function
+// that removes the subscription.
+func (r *Reconciler) Subscribe(fn func(StatusEvent)) (unsubscribe func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := r.nextID
+	r.nextID++
+	r.subscribers[id] = fn
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.subscribers, id)
+	}
+}
+
+// PollTransaction polls pollURL with the Reconciler's Client and ingests the
+// result. The response and error are those of Client.PollTransaction.
+func (r *Reconciler) PollTransaction(ctx context.Context, pollURL string) (*StatusResponse, error) {
+	resp, err := r.client.PollTransaction(ctx, pollURL)
+	if resp != nil {
+		r.Ingest(SourcePoll, resp)
+	}
+	return resp, err
+}
+
+// ProcessStatusUpdate verifies a result-URL body with the Reconciler's Client
+// and ingests the result. The response and error are those of
+// Client.ProcessStatusUpdate.
+func (r *Reconciler) ProcessStatusUpdate(rawBody string) (*StatusResponse, error) {
+	resp, err := r.client.ProcessStatusUpdate(rawBody)
+	if resp != nil {
+		r.Ingest(SourceWebhook, resp)
+	}
+	return resp, err
+}
+
+// Ingest feeds an already-verified status response into the Reconciler and
+// reports whether it caused a StatusEvent. Error responses are ignored.
+func (r *Reconciler) Ingest(source UpdateSource, resp *StatusResponse) bool {
+	if resp.Error != "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := r.key(resp)
+	if key == "" {
+		return false
+	}
+
+	state, known := r.states[key]
+	if resp.Hash != "" && state.hashes[resp.Hash] {
+		return false
+	}
+
+	var next TransactionStatus
+	if known {
+		var err error
+		if next, err = r.statuses.merge(state.status, resp); err != nil || next == state.status {
+			return false
+		}
+	} else {
+		next = resp.Status.canonical()
+		state.hashes = make(map[string]bool)
+	}
+
+	if resp.Hash != "" {
+		state.hashes[resp.Hash] = true
+	}
+	transition := StatusTransition{From: state.status, To: next}
+	state.status = next
+	r.states[key] = state
+
+	if known {
+		r.statuses.notify(transition)
+	}
+	event := StatusEvent{Reference: key, Transition: transition, Source: source, Response: resp}
+	for id := 0; id < r.nextID; id++ {
+		if fn, ok := r.subscribers[id]; ok {
+			fn(event)
+		}
+	}
+	return true
+}
+
+// key returns the key a response is tracked under: its merchant reference,
+// resolving a bare Paynow reference through previously seen responses that
+// carried both. When a response first links the two, state tracked under the
+// bare Paynow reference moves to the merchant reference. The caller must hold
+// the lock.
+func (r *Reconciler) key(resp *StatusResponse) string {
+	if resp.Reference == "" {
+		if ref, ok := r.aliases[resp.PaynowReference]; ok {
+			return ref
+		}
+		return resp.PaynowReference
+	}
+
+	if resp.PaynowReference != "" && resp.PaynowReference != resp.Reference {
+		if orphan, ok := r.states[resp.PaynowReference]; ok {
+			if state, ok := r.states[resp.Reference]; ok {
+				for hash := range orphan.hashes {
+					state.hashes[hash] = true
+				}
+			} else {
+				r.states[resp.Reference] = orphan
+			}
+			delete(r.states, resp.PaynowReference)
+		}
+		r.aliases[resp.PaynowReference] = resp.Reference
+	}
+	return resp.Reference
+}
+
+// Status returns the last status the Reconciler accepted for reference.
+func (r *Reconciler) Status(reference string) (TransactionStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.states[reference]
+	return state.status, ok
+}
+
+// Forget drops everything the Reconciler remembers about reference. A later
+// update for it is treated as the first one seen.
+func (r *Reconciler) Forget(reference string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.states, reference)
+	for alias, ref := range r.aliases {
+		if ref == reference {
+			delete(r.aliases, alias)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package paynow_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestReconciler_EmitsOncePerChange(t *testing.T) {
+	doer := &mockDoer{response: statusBody(testKey, "Pending")}
+	r := paynow.NewReconciler(newTestClient(doer))
+
+	var events []paynow.StatusEvent
+	r.Subscribe(func(e paynow.StatusEvent) { events = append(events, e) })
+
+	ctx := context.Background()
+	poll := "https://www.paynow.co.zw/interface/poll/1"
+
+	_, _ = r.PollTransaction(ctx, poll)
+	_, _ = r.PollTransaction(ctx, poll) // identical poll: duplicate hash
+	_, _ = r.ProcessStatusUpdate(statusBody(testKey, "Paid"))
+	_, _ = r.ProcessStatusUpdate(statusBody(testKey, "Paid")) // Paynow retrying the webhook
+	_, _ = r.PollTransaction(ctx, poll)                       // stale poll still says Pending: duplicate hash
+
+	// A stale poll with a new hash gets past hash deduplication and must be
+	// rejected by the StatusMachine.
+	doer.response = statusBody(testKey, "Sent")
+	if resp, err := r.PollTransaction(ctx, poll); err != nil || resp.Status != paynow.StatusSent {
+		t.Fatalf("PollTransaction() = %+v, %v; want a Sent response", resp, err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].Transition.To != paynow.StatusPending || events[0].Source != paynow.SourcePoll {
+		t.Errorf("first event = %+v, want Pending from a poll", events[0])
+	}
+	if events[1].Transition != (paynow.StatusTransition{From: paynow.StatusPending, To: paynow.StatusPaid}) || events[1].Source != paynow.SourceWebhook {
+		t.Errorf("second event = %+v, want Pending -> Paid from a webhook", events[1])
+	}
+	if status, _ := r.Status("INV-1"); status != paynow.StatusPaid {
+		t.Errorf("Status() = %q, want Paid", status)
+	}
+}
+
+func TestReconciler_ResolvesPaynowReference(t *testing.T) {
+	r := paynow.NewReconciler(newTestClient(&mockDoer{}))
+
+	var events []paynow.StatusEvent
+	unsubscribe := r.Subscribe(func(e paynow.StatusEvent) { events = append(events, e) })
+
+	r.Ingest(paynow.SourcePoll, &paynow.StatusResponse{Reference: "INV-1", PaynowReference: "PN-1", Status: paynow.StatusPaid})
+	if r.Ingest(paynow.SourceWebhook, &paynow.StatusResponse{PaynowReference: "PN-1", Status: paynow.StatusPaid}) {
+		t.Error("an update identified only by the Paynow reference should be deduplicated against INV-1")
+	}
+
+	unsubscribe()
+	r.Ingest(paynow.SourceWebhook, &paynow.StatusResponse{PaynowReference: "PN-1", Status: paynow.StatusDelivered})
+	if len(events) != 1 {
+		t.Errorf("got %d events, want 1 (none after unsubscribing)", len(events))
+	}
+	if status, _ := r.Status("INV-1"); status != paynow.StatusDelivered {
+		t.Errorf("Status() = %q, want Delivered", status)
+	}
+}
+
+func TestReconciler_MovesStateWhenReferencesLink(t *testing.T) {
+	r := paynow.NewReconciler(newTestClient(&mockDoer{}))
+
+	var events []paynow.StatusEvent
+	r.Subscribe(func(e paynow.StatusEvent) { events = append(events, e) })
+
+	r.Ingest(paynow.SourceWebhook, &paynow.StatusResponse{PaynowReference: "PN-1", Status: paynow.StatusSent, Hash: "A"})
+	r.Ingest(paynow.SourcePoll, &paynow.StatusResponse{Reference: "INV-1", PaynowReference: "PN-1", Status: paynow.StatusPaid, Hash: "B"})
+	if r.Ingest(paynow.SourcePoll, &paynow.StatusResponse{Reference: "INV-1", Status: paynow.StatusSent, Hash: "C"}) {
+		t.Error("a stale Sent after Paid should be rejected")
+	}
+
+	if len(events) != 2 || events[1].Transition != (paynow.StatusTransition{From: paynow.StatusSent, To: paynow.StatusPaid}) {
+		t.Fatalf("events = %+v, want Sent then Sent -> Paid under one state", events)
+	}
+	if events[1].Reference != "INV-1" {
+		t.Errorf("second event reference = %q, want INV-1", events[1].Reference)
+	}
+	if _, ok := r.Status("PN-1"); ok {
+		t.Error("state should have moved from the Paynow reference to INV-1")
+	}
+}
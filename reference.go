@@ -0,0 +1,202 @@
+package paynow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// referenceSeparator joins the parts of a generated reference.
+const referenceSeparator = "-"
+
+// maxReferenceAttempts bounds how many references Generate tries when the
+// Store reports collisions.
+const maxReferenceAttempts = 5
+
+// crockford is Crockford's base32 alphabet, which avoids ambiguous characters
+// and sorts in the same order as the values it encodes.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Reference generator errors.
+var (
+	// ErrReferenceCollision is returned by ReferenceGenerator when every
+	// candidate reference it tried already exists in its Store.
+	ErrReferenceCollision = errors.New("paynow: could not generate an unused reference")
+
+	// ErrMalformedReference is returned by ReferenceGenerator.Parse when a
+	// reference was not produced by the generator.
+	ErrMalformedReference = errors.New("paynow: reference was not generated with this prefix")
+
+	// ErrInvalidReferencePrefix is returned by ReferenceGenerator when its
+	// Prefix contains the "-" separator, which would make references
+	// ambiguous to parse.
+	ErrInvalidReferencePrefix = errors.New(`paynow: reference prefix must not contain "-"`)
+)
+
+// Counter supplies sequence numbers for a ReferenceGenerator. Back it with a
+// database sequence to get gap-free, human-friendly references across
+// processes.
+type Counter interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+// MemoryCounter is a Counter that counts up from one in memory. It is safe for
+// concurrent use.
+type MemoryCounter struct {
+	n atomic.Uint64
+}
+
+// Next implements Counter.
+func (c *MemoryCounter) Next(context.Context) (uint64, error) {
+	return c.n.Add(1), nil
+}
+
+// ReferenceGenerator produces unique merchant references of the form
+// PREFIX-ORDERID-UNIQUE, or PREFIX-UNIQUE when there is no order ID. UNIQUE is
+// a sequence number from Counter when one is set, and otherwise a
+// time-ordered, 16-character ID (milliseconds since the epoch plus 32 random
+// bits, in Crockford base32) so references sort by creation time.
+//
+// When Store is set every candidate is checked against it, and another is
+// tried if it is already taken. The zero value generates time-ordered
+// references without a prefix. A ReferenceGenerator is safe for concurrent use.
+type ReferenceGenerator struct {
+	// Prefix starts every reference, for example "INV". It must not contain
+	// "-"; the generator returns ErrInvalidReferencePrefix if it does.
+	Prefix string
+
+	// Counter, if set, supplies sequence numbers instead of time-ordered IDs.
+	Counter Counter
+
+	// Store, if set, is checked so a reference already in use is not returned.
+	Store Store
+
+	// Now, if set, supplies the time embedded in time-ordered IDs. It defaults
+	// to time.Now.
+	Now func() time.Time
+}
+
+// Generate returns a new reference that is not tied to an order.
+func (g *ReferenceGenerator) Generate(ctx context.Context) (string, error) {
+	return g.ForOrder(ctx, "")
+}
+
+// ForOrder returns a new reference embedding orderID, which Parse can recover.
+// The order ID may itself contain "-".
+func (g *ReferenceGenerator) ForOrder(ctx context.Context, orderID string) (string, error) {
+	if strings.Contains(g.Prefix, referenceSeparator) {
+		return "", ErrInvalidReferencePrefix
+	}
+	for attempt := 0; attempt < maxReferenceAttempts; attempt++ {
+		unique, err := g.unique(ctx)
+		if err != nil {
+			return "", err
+		}
+		reference := g.join(orderID, unique)
+
+		if g.Store == nil {
+			return reference, nil
+		}
+		_, err = g.Store.FindByReference(ctx, reference)
+		if errors.Is(err, ErrTransactionNotFound) {
+			return reference, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("paynow: failed to check reference: %w", err)
+		}
+	}
+	return "", ErrReferenceCollision
+}
+
+// join assembles the non-empty parts of a reference.
+func (g *ReferenceGenerator) join(orderID, unique string) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{g.Prefix, orderID, unique} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, referenceSeparator)
+}
+
+// unique returns the UNIQUE part of a new reference.
+func (g *ReferenceGenerator) unique(ctx context.Context) (string, error) {
+	if g.Counter != nil {
+		n, err := g.Counter.Next(ctx)
+		if err != nil {
+			return "", fmt.Errorf("paynow: failed to get next reference number: %w", err)
+		}
+		return fmt.Sprintf("%08d", n), nil
+	}
+
+	now := time.Now
+	if g.Now != nil {
+		now = g.Now
+	}
+
+	var random [4]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", fmt.Errorf("paynow: failed to generate reference: %w", err)
+	}
+	return timeOrderedID(now(), binary.BigEndian.Uint32(random[:])), nil
+}
+
+// timeOrderedID encodes a 48-bit millisecond timestamp followed by 32 random
+// bits as 16 Crockford base32 characters.
+func timeOrderedID(t time.Time, random uint32) string {
+	millis := uint64(t.UnixMilli()) & (1<<48 - 1)
+	hi := millis >> 8                        // top 40 bits
+	lo := (millis&0xFF)<<32 | uint64(random) // low 8 bits of time, then random
+
+	var out [16]byte
+	for i := 7; i >= 0; i-- {
+		out[i] = crockford[hi&31]
+		hi >>= 5
+	}
+	for i := 15; i >= 8; i-- {
+		out[i] = crockford[lo&31]
+		lo >>= 5
+	}
+	return string(out[:])
+}
+
+// ParsedReference holds the parts of a reference produced by a
+// ReferenceGenerator.
+type ParsedReference struct {
+	Prefix  string
+	OrderID string
+	Unique  string
+}
+
+// Parse splits a reference produced by the generator back into its parts, so
+// the order ID can be recovered from the reference Paynow reports. It returns
+// ErrMalformedReference if the reference does not start with the generator's
+// prefix, and ErrInvalidReferencePrefix if the prefix contains "-".
+func (g *ReferenceGenerator) Parse(reference string) (ParsedReference, error) {
+	if strings.Contains(g.Prefix, referenceSeparator) {
+		return ParsedReference{}, ErrInvalidReferencePrefix
+	}
+	rest := reference
+	if g.Prefix != "" {
+		var ok bool
+		if rest, ok = strings.CutPrefix(reference, g.Prefix+referenceSeparator); !ok {
+			return ParsedReference{}, ErrMalformedReference
+		}
+	}
+
+	parsed := ParsedReference{Prefix: g.Prefix}
+	if i := strings.LastIndex(rest, referenceSeparator); i >= 0 {
+		parsed.OrderID, parsed.Unique = rest[:i], rest[i+1:]
+	} else {
+		parsed.Unique = rest
+	}
+	if parsed.Unique == "" {
+		return ParsedReference{}, ErrMalformedReference
+	}
+	return parsed, nil
+}
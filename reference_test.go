@@ -0,0 +1,101 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestReferenceGenerator_TimeOrdered(t *testing.T) {
+	g := &paynow.ReferenceGenerator{Prefix: "INV"}
+	ctx := context.Background()
+
+	a, err := g.ForOrder(ctx, "ORD-77")
+	if err != nil {
+		t.Fatalf("ForOrder() error = %v", err)
+	}
+	b, _ := g.ForOrder(ctx, "ORD-77")
+	if a == b {
+		t.Errorf("ForOrder() returned %q twice", a)
+	}
+	if !strings.HasPrefix(a, "INV-ORD-77-") || len(a) != len("INV-ORD-77-")+16 {
+		t.Errorf("ForOrder() = %q, want INV-ORD-77- and a 16 character ID", a)
+	}
+
+	parsed, err := g.Parse(a)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.OrderID != "ORD-77" || parsed.Prefix != "INV" || len(parsed.Unique) != 16 {
+		t.Errorf("Parse(%q) = %+v", a, parsed)
+	}
+}
+
+func TestReferenceGenerator_CounterAndCollisions(t *testing.T) {
+	store := newSeededStore(t, &paynow.Transaction{Reference: "SHOP-00000001", Status: paynow.StatusSent})
+	ctx := context.Background()
+
+	g := &paynow.ReferenceGenerator{Prefix: "SHOP", Counter: &paynow.MemoryCounter{}, Store: store}
+	ref, err := g.Generate(ctx)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if ref != "SHOP-00000002" {
+		t.Errorf("Generate() = %q, want SHOP-00000002 (00000001 is taken)", ref)
+	}
+
+	parsed, err := g.Parse(ref)
+	if err != nil || parsed.OrderID != "" || parsed.Unique != "00000002" {
+		t.Errorf("Parse(%q) = %+v, %v", ref, parsed, err)
+	}
+}
+
+type stuckCounter struct{}
+
+func (stuckCounter) Next(context.Context) (uint64, error) { return 1, nil }
+
+func TestReferenceGenerator_GivesUpOnCollisions(t *testing.T) {
+	store := newSeededStore(t, &paynow.Transaction{Reference: "X-00000001", Status: paynow.StatusSent})
+	ctx := context.Background()
+
+	g := &paynow.ReferenceGenerator{Prefix: "X", Counter: stuckCounter{}, Store: store}
+	if _, err := g.Generate(ctx); !errors.Is(err, paynow.ErrReferenceCollision) {
+		t.Errorf("Generate() error = %v, want ErrReferenceCollision", err)
+	}
+}
+
+func TestReferenceGenerator_ParseRejectsForeignReferences(t *testing.T) {
+	g := &paynow.ReferenceGenerator{Prefix: "INV"}
+	for _, ref := range []string{"OTHER-123", "INV-", "INV"} {
+		if _, err := g.Parse(ref); !errors.Is(err, paynow.ErrMalformedReference) {
+			t.Errorf("Parse(%q) error = %v, want ErrMalformedReference", ref, err)
+		}
+	}
+}
+
+func TestReferenceGenerator_Now(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := &paynow.ReferenceGenerator{Now: func() time.Time { return at }}
+	ctx := context.Background()
+
+	first, _ := g.Generate(ctx)
+	at = at.Add(time.Hour)
+	later, _ := g.Generate(ctx)
+	if first[:8] == later[:8] || first > later {
+		t.Errorf("Generate() = %q then %q, want the injected clock to order them", first, later)
+	}
+}
+
+func TestReferenceGenerator_RejectsPrefixWithSeparator(t *testing.T) {
+	g := &paynow.ReferenceGenerator{Prefix: "INV-A"}
+	if _, err := g.Generate(context.Background()); !errors.Is(err, paynow.ErrInvalidReferencePrefix) {
+		t.Errorf("Generate() error = %v, want ErrInvalidReferencePrefix", err)
+	}
+	if _, err := g.Parse("INV-A-00000001"); !errors.Is(err, paynow.ErrInvalidReferencePrefix) {
+		t.Errorf("Parse() error = %v, want ErrInvalidReferencePrefix", err)
+	}
+}
@@ -0,0 +1,179 @@
+package paynow
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrUnknownMerchant is returned by Registry when no Client is registered for a
+// merchant, or when no registered integration key verifies a status update.
+var ErrUnknownMerchant = errors.New("paynow: no client registered for merchant")
+
+// Registry holds a Client per merchant for services that process payments on
+// behalf of several Paynow integrations, and routes calls to the right one by
+// merchant key. The key is any string you choose, such as a merchant or
+// integration ID. Create one with NewRegistry. A Registry is safe for
+// concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	clients map[string]*Client
+	order   []string // merchant keys in registration order
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{clients: make(map[string]*Client)}
+}
+
+// Register adds client under merchant, replacing any Client already registered
+// for it.
+func (r *Registry) Register(merchant string, client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[merchant]; !ok {
+		r.order = append(r.order, merchant)
+	}
+	r.clients[merchant] = client
+}
+
+// Remove unregisters merchant. It does nothing if merchant is not registered.
+func (r *Registry) Remove(merchant string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clients[merchant]; !ok {
+		return
+	}
+	delete(r.clients, merchant)
+	for i, key := range r.order {
+		if key == merchant {
+			r.order = append(r.order[:i:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Merchants returns the registered merchant keys in registration order.
+func (r *Registry) Merchants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.order...)
+}
+
+// Client returns the Client registered for merchant, or ErrUnknownMerchant.
+func (r *Registry) Client(merchant string) (*Client, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[merchant]
+	if !ok {
+		return nil, ErrUnknownMerchant
+	}
+	return client, nil
+}
+
+// Send initiates a web transaction with merchant's Client. See Client.Send.
+func (r *Registry) Send(ctx context.Context, merchant string, payment *Payment) (*InitResponse, error) {
+	client, err := r.Client(merchant)
+	if err != nil {
+		return nil, err
+	}
+	return client.Send(ctx, payment)
+}
+
+// SendMobile initiates a mobile transaction with merchant's Client. See
+// Client.SendMobile.
+func (r *Registry) SendMobile(ctx context.Context, merchant string, payment *Payment, phone string, method PaymentMethod) (*InitResponse, error) {
+	client, err := r.Client(merchant)
+	if err != nil {
+		return nil, err
+	}
+	return client.SendMobile(ctx, payment, phone, method)
+}
+
+// PollTransaction polls a transaction with merchant's Client. See
+// Client.PollTransaction.
+func (r *Registry) PollTransaction(ctx context.Context, merchant, pollURL string) (*StatusResponse, error) {
+	client, err := r.Client(merchant)
+	if err != nil {
+		return nil, err
+	}
+	return client.PollTransaction(ctx, pollURL)
+}
+
+// ResolveStatusUpdate finds the merchant a status update posted to a result
+// URL belongs to. When hint is non-empty it is taken as the merchant key, for
+// example one carried in the result URL ("/paynow/result?merchant=acme").
+// Otherwise the update's hash is checked against each registered integration
+// key in registration order, and the first that verifies wins.
+//
+// It returns ErrUnknownMerchant if the hint is not registered or no key
+// verifies the update. Error updates from Paynow carry no hash, so they can
+// only be routed with a hint; without one their message is returned as an
+// *APIError.
+func (r *Registry) ResolveStatusUpdate(hint, rawBody string) (string, *Client, error) {
+	return r.resolve(context.Background(), hint, rawBody)
+}
+
+// resolve is ResolveStatusUpdate with a context for the clients' KeyProviders.
+func (r *Registry) resolve(ctx context.Context, hint, rawBody string) (string, *Client, error) {
+	if hint != "" {
+		client, err := r.Client(hint)
+		if err != nil {
+			return "", nil, err
+		}
+		return hint, client, nil
+	}
+
+	values, err := parseResponse(rawBody)
+	if err != nil {
+		return "", nil, err
+	}
+	status, _ := values.get("status")
+	if equalFoldTrim(status, responseError) {
+		resp := newStatusResponse(values)
+		return "", nil, &APIError{Message: resp.Error}
+	}
+
+	// Try the keys without holding the lock, since a KeyProvider or Signer may
+	// read files or call out over the network.
+	type candidate struct {
+		merchant string
+		client   *Client
+	}
+	r.mu.RLock()
+	candidates := make([]candidate, len(r.order))
+	for i, merchant := range r.order {
+		candidates[i] = candidate{merchant, r.clients[merchant]}
+	}
+	r.mu.RUnlock()
+
+	for _, c := range candidates {
+		if _, err := c.client.matchKey(ctx, values); err == nil {
+			return c.merchant, c.client, nil
+		}
+	}
+	return "", nil, ErrUnknownMerchant
+}
+
+// ProcessStatusUpdate routes a status update with ResolveStatusUpdate and
+// processes it with the merchant's Client.ProcessStatusUpdate. It returns the
+// merchant the update belongs to.
+func (r *Registry) ProcessStatusUpdate(hint, rawBody string) (string, *StatusResponse, error) {
+	merchant, client, err := r.ResolveStatusUpdate(hint, rawBody)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := client.ProcessStatusUpdate(rawBody)
+	return merchant, resp, err
+}
+
+// ProcessStatusUpdateOnce is like ProcessStatusUpdate, but processes the update
+// with Client.ProcessStatusUpdateOnce so replays are detected.
+func (r *Registry) ProcessStatusUpdateOnce(ctx context.Context, hint, rawBody string) (string, *StatusResponse, error) {
+	merchant, client, err := r.resolve(ctx, hint, rawBody)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := client.ProcessStatusUpdateOnce(ctx, rawBody)
+	return merchant, resp, err
+}
@@ -0,0 +1,88 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+const otherKey = "77aa01f4-other-integration-key"
+
+func newTestRegistry(doer paynow.Doer) *paynow.Registry {
+	r := paynow.NewRegistry()
+	r.Register("acme", newTestClient(doer))
+	r.Register("globex", paynow.New("67890", otherKey, paynow.WithHTTPClient(doer)))
+	return r
+}
+
+func TestRegistry_RoutesByMerchant(t *testing.T) {
+	doer := &mockDoer{response: signResponse(otherKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	r := newTestRegistry(doer)
+
+	if _, err := r.Send(context.Background(), "globex", paidPayment()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	values, _ := url.ParseQuery(doer.capturedBody)
+	if got := values.Get("id"); got != "67890" {
+		t.Errorf("request id = %q, want globex's integration id", got)
+	}
+
+	if _, err := r.Send(context.Background(), "initech", paidPayment()); !errors.Is(err, paynow.ErrUnknownMerchant) {
+		t.Errorf("Send() to unregistered merchant error = %v, want ErrUnknownMerchant", err)
+	}
+}
+
+func TestRegistry_ResolvesStatusUpdateByKey(t *testing.T) {
+	r := newTestRegistry(&mockDoer{})
+	body := signResponse(otherKey,
+		field{"reference", "INV-1"},
+		field{"amount", "10.00"},
+		field{"paynowreference", "PN-1"},
+		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
+		field{"status", "Paid"},
+	)
+
+	merchant, resp, err := r.ProcessStatusUpdate("", body)
+	if err != nil {
+		t.Fatalf("ProcessStatusUpdate() error = %v", err)
+	}
+	if merchant != "globex" || !resp.Paid {
+		t.Errorf("ProcessStatusUpdate() = %q, paid %v; want globex, paid", merchant, resp.Paid)
+	}
+
+	forged := signResponse("not-a-registered-key", field{"reference", "INV-1"}, field{"status", "Paid"})
+	if _, _, err := r.ProcessStatusUpdate("", forged); !errors.Is(err, paynow.ErrUnknownMerchant) {
+		t.Errorf("forged update error = %v, want ErrUnknownMerchant", err)
+	}
+}
+
+func TestRegistry_HintIsAuthoritative(t *testing.T) {
+	r := newTestRegistry(&mockDoer{})
+
+	// Signed by globex but routed to acme by the hint: acme's key must reject it.
+	body := signResponse(otherKey, field{"reference", "INV-1"}, field{"status", "Paid"})
+	if _, _, err := r.ProcessStatusUpdate("acme", body); !errors.Is(err, paynow.ErrHashMismatch) {
+		t.Errorf("ProcessStatusUpdate() error = %v, want ErrHashMismatch", err)
+	}
+	if _, _, err := r.ProcessStatusUpdateOnce(context.Background(), "initech", body); !errors.Is(err, paynow.ErrUnknownMerchant) {
+		t.Errorf("ProcessStatusUpdateOnce() error = %v, want ErrUnknownMerchant", err)
+	}
+}
+
+func TestRegistry_RegisterAndRemove(t *testing.T) {
+	r := newTestRegistry(&mockDoer{})
+	r.Register("acme", paynow.New("99999", testKey))
+	r.Remove("acme")
+	r.Remove("initech")
+
+	if got := r.Merchants(); !reflect.DeepEqual(got, []string{"globex"}) {
+		t.Errorf("Merchants() = %v, want [globex]", got)
+	}
+	if _, err := r.Client("acme"); !errors.Is(err, paynow.ErrUnknownMerchant) {
+		t.Errorf("Client() error = %v, want ErrUnknownMerchant", err)
+	}
+}
@@ -1,13 +1,26 @@
 package paynow
 
-import "github.com/IamTyrone/paynow-go/internal/hash"
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// ReferencePlaceholder is replaced with the payment's escaped reference in
+// result and return URLs, whether they are set on the Client or the Payment:
+// "https://example.com/orders/{reference}" or
+// "https://example.com/return?ref={reference}". The reference is path-escaped
+// in the path and query-escaped in the query.
+const ReferencePlaceholder = "{reference}"
 
 // buildWeb assembles the fields for a normal web-based transaction, in the order
 // Paynow expects, and appends the request hash.
-func (c *Client) buildWeb(payment *Payment) *orderedValues {
+func (c *Client) buildWeb(ctx context.Context, payment *Payment) (*orderedValues, error) {
+	resultURL, returnURL := c.urlsFor(payment)
+
 	data := newOrderedValues()
-	data.set("resulturl", c.resultURL)
-	data.set("returnurl", c.returnURL)
+	data.set("resulturl", resultURL)
+	data.set("returnurl", returnURL)
 	data.set("reference", payment.Reference)
 	data.set("amount", formatAmount(payment.Total()))
 	data.set("id", c.integrationID)
@@ -15,30 +28,58 @@ func (c *Client) buildWeb(payment *Payment) *orderedValues {
 	data.set("authemail", payment.AuthEmail)
 	data.set("status", "Message")
 
-	c.sign(data)
-	return data
+	return data, c.sign(ctx, data)
 }
 
 // buildMobile assembles the fields for an express-checkout mobile transaction,
-// in the order Paynow expects, and appends the request hash.
-func (c *Client) buildMobile(payment *Payment, phone string, method PaymentMethod) *orderedValues {
+// in the order Paynow expects, and appends the request hash. The phone number
+// and method are sent normalized, as ValidateMobile checked them.
+func (c *Client) buildMobile(ctx context.Context, payment *Payment, phone string, method PaymentMethod) (*orderedValues, error) {
+	resultURL, returnURL := c.urlsFor(payment)
+
 	data := newOrderedValues()
-	data.set("resulturl", c.resultURL)
-	data.set("returnurl", c.returnURL)
+	data.set("resulturl", resultURL)
+	data.set("returnurl", returnURL)
 	data.set("reference", payment.Reference)
 	data.set("amount", formatAmount(payment.Total()))
 	data.set("id", c.integrationID)
 	data.set("additionalinfo", payment.Info())
 	data.set("authemail", payment.AuthEmail)
-	data.set("phone", phone)
-	data.set("method", method.String())
+	data.set("phone", normalizePhone(phone))
+	data.set("method", method.normalize().String())
 	data.set("status", "Message")
 
-	c.sign(data)
-	return data
+	return data, c.sign(ctx, data)
+}
+
+// urlsFor returns the result and return URLs for payment: its own where set,
+// otherwise the Client's, with ReferencePlaceholder expanded.
+func (c *Client) urlsFor(payment *Payment) (resultURL, returnURL string) {
+	resultURL, returnURL = c.urls()
+	resultURL = expandURL(firstNonEmpty(payment.ResultURL, resultURL), payment.Reference)
+	returnURL = expandURL(firstNonEmpty(payment.ReturnURL, returnURL), payment.Reference)
+	return resultURL, returnURL
+}
+
+// expandURL replaces ReferencePlaceholder in template with the reference,
+// path-escaped before the query and query-escaped within it, so "INV 1" becomes
+// "/orders/INV%201" but "?ref=INV+1".
+func expandURL(template, reference string) string {
+	path, query, hasQuery := strings.Cut(template, "?")
+	path = strings.ReplaceAll(path, ReferencePlaceholder, url.PathEscape(reference))
+	if !hasQuery {
+		return path
+	}
+	return path + "?" + strings.ReplaceAll(query, ReferencePlaceholder, url.QueryEscape(reference))
 }
 
-// sign computes the request hash over the current values and appends it.
-func (c *Client) sign(data *orderedValues) {
-	data.set("hash", hash.Make(data.signingValues(), c.integrationKey))
+// sign computes the request hash over the current values with the Client's
+// Signer and appends it.
+func (c *Client) sign(ctx context.Context, data *orderedValues) error {
+	h, err := c.signVerifier().Sign(ctx, data.signingValues())
+	if err != nil {
+		return err
+	}
+	data.set("hash", h)
+	return nil
 }
@@ -6,40 +6,44 @@ import "strconv"
 // Client.SendMobile.
 type InitResponse struct {
 	// Status is the raw status Paynow returned (for example "Ok" or "Error").
-	Status string
+	Status string `json:"status"`
 
 	// Success reports whether Paynow accepted the request.
-	Success bool
+	Success bool `json:"success"`
 
 	// HasRedirect reports whether RedirectURL is set. For web transactions the
 	// customer must be redirected there to complete payment.
-	HasRedirect bool
+	HasRedirect bool `json:"has_redirect"`
 
 	// RedirectURL is the URL to send the customer to so they can pay. Only set
 	// for web transactions.
-	RedirectURL string
+	RedirectURL string `json:"redirect_url,omitempty"`
 
 	// PollURL is the URL to poll (via Client.PollTransaction) to check the
 	// transaction's status.
-	PollURL string
+	PollURL string `json:"poll_url,omitempty"`
 
 	// Instructions holds USSD push instructions for the customer to dial, for
 	// some mobile money payments.
-	Instructions string
+	Instructions string `json:"instructions,omitempty"`
 
 	// Error holds Paynow's error message when Success is false.
-	Error string
+	Error string `json:"error,omitempty"`
 
 	// Hash is the raw hash Paynow sent with the response.
-	Hash string
+	Hash string `json:"hash,omitempty"`
+
+	// KeyIndex identifies the integration key that verified Hash: 0 for the
+	// primary key, or i+1 for the i-th key passed to WithPreviousKeys.
+	KeyIndex int `json:"key_index"`
 
 	// InnBucks holds InnBucks-specific payment details when the response is for
 	// an InnBucks transaction, and is nil otherwise.
-	InnBucks *InnBucksInfo
+	InnBucks *InnBucksInfo `json:"innbucks,omitempty"`
 
 	// Raw exposes every field Paynow returned, for access to fields the SDK does
 	// not model explicitly.
-	Raw map[string]string
+	Raw map[string]string `json:"raw,omitempty"`
 }
 
 // InnBucksInfo holds the details needed to complete an InnBucks payment. Paynow
@@ -47,16 +51,16 @@ type InitResponse struct {
 // scannable QR code.
 type InnBucksInfo struct {
 	// AuthorizationCode is the InnBucks payment code.
-	AuthorizationCode string
+	AuthorizationCode string `json:"authorization_code"`
 
 	// DeepLinkURL opens the InnBucks app pre-filled with the payment code.
-	DeepLinkURL string
+	DeepLinkURL string `json:"deep_link_url"`
 
 	// QRCodeURL renders a QR code encoding the payment code.
-	QRCodeURL string
+	QRCodeURL string `json:"qr_code_url"`
 
 	// ExpiresAt is when the authorization code expires, as returned by Paynow.
-	ExpiresAt string
+	ExpiresAt string `json:"expires_at,omitempty"`
 }
 
 // newInitResponse builds an InitResponse from parsed, hash-verified values.
@@ -102,31 +106,35 @@ func newInitResponse(ov *orderedValues) *InitResponse {
 // update from Paynow.
 type StatusResponse struct {
 	// Status is the transaction's status as reported by Paynow.
-	Status TransactionStatus
+	Status TransactionStatus `json:"status"`
 
 	// Paid is a convenience flag equivalent to Status.IsPaid.
-	Paid bool
+	Paid bool `json:"paid"`
 
 	// Amount is the transaction amount.
-	Amount float64
+	Amount float64 `json:"amount"`
 
 	// Reference is the merchant's reference for the transaction.
-	Reference string
+	Reference string `json:"reference"`
 
 	// PaynowReference is Paynow's own reference for the transaction.
-	PaynowReference string
+	PaynowReference string `json:"paynow_reference,omitempty"`
 
 	// PollURL is the URL that can be polled to re-check the transaction status.
-	PollURL string
+	PollURL string `json:"poll_url,omitempty"`
 
 	// Hash is the raw hash Paynow sent with the response.
-	Hash string
+	Hash string `json:"hash,omitempty"`
+
+	// KeyIndex identifies the integration key that verified Hash: 0 for the
+	// primary key, or i+1 for the i-th key passed to WithPreviousKeys.
+	KeyIndex int `json:"key_index"`
 
 	// Error holds Paynow's error message, if any.
-	Error string
+	Error string `json:"error,omitempty"`
 
 	// Raw exposes every field Paynow returned.
-	Raw map[string]string
+	Raw map[string]string `json:"raw,omitempty"`
 }
 
 // newStatusResponse builds a StatusResponse from parsed values.
@@ -6,35 +6,63 @@ import "context"
 // InitResponse carries a RedirectURL the customer should be sent to in order to
 // complete payment, and a PollURL for checking the transaction status.
 //
-// A non-nil error is returned for transport failures, malformed or
-// hash-mismatched responses, and for business errors reported by Paynow (as an
-// *APIError). Even when Paynow reports a business error the InitResponse is
-// returned populated so its Error field can be inspected.
+// The payment is checked with Payment.Validate first; problems are returned as
+// a *ValidationError without contacting Paynow. A non-nil error is also
+// returned for transport failures, malformed or hash-mismatched responses, and
+// for business errors reported by Paynow (as an *APIError). Even when Paynow
+// reports a business error the InitResponse is returned populated so its Error
+// field can be inspected. Likewise, if the transaction was initiated but could
+// not be saved to the Client's Store, the InitResponse is returned alongside
+// the error.
 func (c *Client) Send(ctx context.Context, payment *Payment) (*InitResponse, error) {
-	if err := validatePayment(payment); err != nil {
+	if payment == nil {
+		return nil, ErrNoPayment
+	}
+	if err := payment.Validate(); err != nil {
 		return nil, err
 	}
 
-	body := c.buildWeb(payment).encode()
-	return c.initiate(ctx, urlInitiateTransaction, body)
+	data, err := c.buildWeb(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.initiate(ctx, urlInitiateTransaction, data.encode())
+	if err != nil {
+		return resp, err
+	}
+	return resp, c.recordInitiated(ctx, payment, "", resp)
 }
 
 // SendMobile initiates an express-checkout mobile money transaction for the
-// given phone number and method (for example paynow.MethodEcocash). Mobile
-// transactions require a valid auth email on the payment.
+// given phone number and method (for example paynow.MethodEcocash). The payment
+// is checked with Payment.ValidateMobile first: mobile transactions require a
+// valid auth email and mobile number. The number is sent without spaces or
+// dashes and the method in lower case. A total outside the method's limits is
+// rejected with a *LimitError (see WithMethodLimits).
 //
 // The returned InitResponse carries a PollURL and, depending on the method, USSD
 // Instructions or InnBucks payment details. Error semantics match Send.
 func (c *Client) SendMobile(ctx context.Context, payment *Payment, phone string, method PaymentMethod) (*InitResponse, error) {
-	if err := validatePayment(payment); err != nil {
+	if payment == nil {
+		return nil, ErrNoPayment
+	}
+	if err := payment.ValidateMobile(phone, method); err != nil {
 		return nil, err
 	}
-	if !isValidEmail(payment.AuthEmail) {
-		return nil, ErrInvalidEmail
+	method = method.normalize()
+	if err := c.CheckMethodLimit(method, payment.Total()); err != nil {
+		return nil, err
 	}
 
-	body := c.buildMobile(payment, phone, method).encode()
-	return c.initiate(ctx, urlInitiateMobileTransaction, body)
+	data, err := c.buildMobile(ctx, payment, phone, method)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.initiate(ctx, urlInitiateMobileTransaction, data.encode())
+	if err != nil {
+		return resp, err
+	}
+	return resp, c.recordInitiated(ctx, payment, method, resp)
 }
 
 // initiate posts a built request body to endpoint and parses the response into
@@ -50,30 +78,18 @@ func (c *Client) initiate(ctx context.Context, endpoint, body string) (*InitResp
 		return nil, err
 	}
 
+	keyIndex := 0
 	status, _ := values.get("status")
 	if !equalFoldTrim(status, responseError) {
-		if err := values.verifyHash(c.integrationKey); err != nil {
+		if keyIndex, err = c.verifyHash(ctx, values); err != nil {
 			return nil, err
 		}
 	}
 
 	resp := newInitResponse(values)
+	resp.KeyIndex = keyIndex
 	if !resp.Success {
 		return resp, &APIError{Message: resp.Error}
 	}
 	return resp, nil
 }
-
-// validatePayment performs the sanity checks shared by Send and SendMobile.
-func validatePayment(payment *Payment) error {
-	if payment == nil {
-		return ErrNoPayment
-	}
-	if len(payment.cart.items) == 0 {
-		return ErrEmptyCart
-	}
-	if payment.Total() <= 0 {
-		return ErrNonPositiveTotal
-	}
-	return nil
-}
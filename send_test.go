@@ -86,6 +86,18 @@ func TestSendMobile_Success(t *testing.T) {
 	}
 }
 
+func TestSendMobile_NormalizesPhoneAndMethod(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+
+	if _, err := newTestClient(doer).SendMobile(context.Background(), paidPayment(), "077 123-4567", "EcoCash"); err != nil {
+		t.Fatalf("SendMobile() error = %v", err)
+	}
+	values, _ := url.ParseQuery(doer.capturedBody)
+	if values.Get("method") != "ecocash" || values.Get("phone") != "0771234567" {
+		t.Errorf("sent method=%q phone=%q, want ecocash and 0771234567", values.Get("method"), values.Get("phone"))
+	}
+}
+
 func TestSendMobile_InnBucks(t *testing.T) {
 	doer := &mockDoer{response: signResponse(testKey,
 		field{"status", "Ok"},
@@ -169,3 +181,38 @@ func TestSend_HashMismatch(t *testing.T) {
 		t.Errorf("Send() error = %v, want ErrHashMismatch", err)
 	}
 }
+
+func TestSend_PaymentURLOverrides(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	payment := paynow.NewPayment("INV 1&2", "buyer@example.com").Add("Item", 10.00)
+	payment.ResultURL = "https://tenant.example.com/orders/{reference}/result"
+	payment.ReturnURL = "https://tenant.example.com/return?ref={reference}"
+
+	_, _ = newTestClient(doer).Send(context.Background(), payment)
+
+	values, _ := url.ParseQuery(doer.capturedBody)
+	if got := values.Get("resulturl"); got != "https://tenant.example.com/orders/INV%201&2/result" {
+		t.Errorf("resulturl = %q, want the path-escaped reference substituted", got)
+	}
+	if got := values.Get("returnurl"); got != "https://tenant.example.com/return?ref=INV+1%262" {
+		t.Errorf("returnurl = %q, want the escaped reference substituted", got)
+	}
+}
+
+func TestSendMobile_ClientURLTemplate(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	client := paynow.New("12345", testKey,
+		paynow.WithResultURL("https://example.com/result/{reference}"),
+		paynow.WithHTTPClient(doer),
+	)
+
+	_, _ = client.SendMobile(context.Background(), paidPayment(), "0771234567", paynow.MethodEcocash)
+
+	values, _ := url.ParseQuery(doer.capturedBody)
+	if got := values.Get("resulturl"); got != "https://example.com/result/INV-1" {
+		t.Errorf("resulturl = %q, want the client template expanded", got)
+	}
+	if got := values.Get("returnurl"); got != "" {
+		t.Errorf("returnurl = %q, want empty", got)
+	}
+}
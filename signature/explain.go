@@ -0,0 +1,64 @@
+package signature
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyPlaceholder stands in for the integration key in an Explanation, which
+// never contains the key itself.
+const keyPlaceholder = "<integration key>"
+
+// Explanation shows how a message's hash is computed, for debugging a hash
+// mismatch. It is safe to log: it does not include the integration key.
+type Explanation struct {
+	// Fields are the fields covered by the hash, in the order they were
+	// concatenated.
+	Fields Fields
+
+	// Payload is the concatenated field values that were hashed, followed by
+	// a placeholder for the integration key.
+	Payload string
+
+	// Received is the hash the message carried, if any.
+	Received string
+
+	// Expected is the hash computed from Fields and the integration key.
+	Expected string
+
+	// Match reports whether Received matches Expected.
+	Match bool
+}
+
+// Explain describes how the hash of fields is computed with integrationKey and
+// whether it matches the hash they carry. Common causes of a mismatch are
+// fields re-ordered by url.Values, values decoded or trimmed differently, and
+// the wrong integration key.
+func Explain(fields Fields, integrationKey string) Explanation {
+	e := Explanation{
+		Fields:   fields.Signed(),
+		Expected: Sign(fields, integrationKey),
+	}
+	e.Payload = strings.Join(fields.Values(), "") + keyPlaceholder
+	e.Received, _ = fields.Hash()
+	e.Match = Verify(fields, integrationKey) == nil
+	return e
+}
+
+// String renders the explanation over several lines.
+func (e Explanation) String() string {
+	var b strings.Builder
+	if e.Match {
+		b.WriteString("hash matches\n")
+	} else {
+		b.WriteString("hash does not match\n")
+	}
+	b.WriteString("fields, in hashing order:\n")
+	for i, field := range e.Fields {
+		fmt.Fprintf(&b, "  %d. %s = %q\n", i+1, field.Key, field.Value)
+	}
+	fmt.Fprintf(&b, "payload:  %s\n", e.Payload)
+	fmt.Fprintf(&b, "received: %s\n", e.Received)
+	fmt.Fprintf(&b, "expected: %s", e.Expected)
+	return b.String()
+}
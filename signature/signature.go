@@ -0,0 +1,148 @@
+// Package signature computes and checks the hashes Paynow uses to sign every
+// request, response and status update, for tools that handle Paynow messages
+// without a paynow.Client, such as webhook relays and test harnesses.
+//
+// Paynow's hash is the SHA-512 digest of every field value in the order the
+// fields are sent (excluding the "hash" field itself) followed by the
+// lower-cased integration key, encoded as uppercase hex. Because order matters,
+// messages are handled as Fields rather than url.Values.
+package signature
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/IamTyrone/paynow-go/internal/hash"
+)
+
+// HashField is the name of the field carrying a message's hash.
+const HashField = "hash"
+
+var (
+	// ErrMissingHash is returned by Verify when a message has no hash field.
+	ErrMissingHash = errors.New("paynow: response does not contain a hash")
+
+	// ErrHashMismatch is returned by Verify when a message's hash does not
+	// match the hash computed from its fields.
+	ErrHashMismatch = errors.New("paynow: response hash does not match")
+)
+
+// Field is a single key/value pair of a Paynow message.
+type Field struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Fields is a Paynow message: key/value pairs in the order they are sent.
+type Fields []Field
+
+// Parse parses an application/x-www-form-urlencoded body, such as a status
+// update posted to a result URL, keeping the order of its fields.
+func Parse(body string) (Fields, error) {
+	body = strings.TrimPrefix(strings.TrimSpace(body), "?")
+
+	var fields Fields
+	for _, pair := range strings.Split(body, "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			return nil, err
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	return fields, nil
+}
+
+// Get returns the value of the first field named key and whether there was
+// one.
+func (f Fields) Get(key string) (string, bool) {
+	for _, field := range f {
+		if field.Key == key {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// Hash returns the value of the hash field and whether there was one.
+func (f Fields) Hash() (string, bool) {
+	for _, field := range f {
+		if strings.EqualFold(field.Key, HashField) {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// Signed returns the fields that are covered by the hash: every field except
+// the hash itself, in order.
+func (f Fields) Signed() Fields {
+	out := make(Fields, 0, len(f))
+	for _, field := range f {
+		if !strings.EqualFold(field.Key, HashField) {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// Values returns the values of the signed fields, in order: the input Paynow
+// hashes, before the integration key is appended.
+func (f Fields) Values() []string {
+	signed := f.Signed()
+	out := make([]string, len(signed))
+	for i, field := range signed {
+		out[i] = field.Value
+	}
+	return out
+}
+
+// Encode renders the fields as an application/x-www-form-urlencoded body in
+// order. url.Values.Encode sorts keys, which changes the hash Paynow computes.
+func (f Fields) Encode() string {
+	var b strings.Builder
+	for i, field := range f {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(field.Key))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(field.Value))
+	}
+	return b.String()
+}
+
+// Sign returns the hash of the fields with integrationKey. An existing hash
+// field is ignored.
+func Sign(fields Fields, integrationKey string) string {
+	return hash.Make(fields.Values(), integrationKey)
+}
+
+// Append returns the signed fields followed by a hash field signed with
+// integrationKey, replacing any existing hash.
+func Append(fields Fields, integrationKey string) Fields {
+	return append(fields.Signed(), Field{Key: HashField, Value: Sign(fields, integrationKey)})
+}
+
+// Verify checks the fields' hash against integrationKey. It returns
+// ErrMissingHash if there is no hash field and ErrHashMismatch if it does not
+// match. Use Explain to see why.
+func Verify(fields Fields, integrationKey string) error {
+	received, ok := fields.Hash()
+	if !ok {
+		return ErrMissingHash
+	}
+	if !hash.Equal(received, fields.Values(), integrationKey) {
+		return ErrHashMismatch
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package signature_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go/signature"
+)
+
+const key = "3e9c8b12-integration-key"
+
+func statusFields() signature.Fields {
+	return signature.Fields{
+		{Key: "reference", Value: "INV-1"},
+		{Key: "amount", Value: "10.00"},
+		{Key: "status", Value: "Paid"},
+	}
+}
+
+func TestAppendAndVerify(t *testing.T) {
+	signed := signature.Append(statusFields(), key)
+	if err := signature.Verify(signed, key); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := signature.Verify(signed, "another-key"); !errors.Is(err, signature.ErrHashMismatch) {
+		t.Errorf("Verify() with wrong key error = %v, want ErrHashMismatch", err)
+	}
+	if err := signature.Verify(statusFields(), key); !errors.Is(err, signature.ErrMissingHash) {
+		t.Errorf("Verify() without hash error = %v, want ErrMissingHash", err)
+	}
+
+	resigned := signature.Append(signed, key)
+	if len(resigned) != len(signed) {
+		t.Errorf("Append() on signed fields has %d fields, want the hash replaced", len(resigned))
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	signed := signature.Append(append(statusFields(), signature.Field{Key: "pollurl", Value: "https://x/?a=1&b=2"}), key)
+
+	parsed, err := signature.Parse("?" + signed.Encode() + "\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := signature.Verify(parsed, key); err != nil {
+		t.Errorf("Verify() of parsed body error = %v", err)
+	}
+	if got, _ := parsed.Get("pollurl"); got != "https://x/?a=1&b=2" {
+		t.Errorf("Get(pollurl) = %q", got)
+	}
+	if got := strings.Join(parsed.Values(), ","); got != "INV-1,10.00,Paid,https://x/?a=1&b=2" {
+		t.Errorf("Values() = %q, want the signed values in order without the hash", got)
+	}
+	if _, err := signature.Parse("a=%zz"); err == nil {
+		t.Error("Parse() accepted an invalid escape")
+	}
+}
+
+func TestVerify_OrderMatters(t *testing.T) {
+	signed := signature.Append(statusFields(), key)
+	swapped := signature.Fields{signed[1], signed[0], signed[2], signed[3]}
+	if err := signature.Verify(swapped, key); !errors.Is(err, signature.ErrHashMismatch) {
+		t.Errorf("Verify() of reordered fields error = %v, want ErrHashMismatch", err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	fields := signature.Append(statusFields(), "another-key")
+	e := signature.Explain(fields, key)
+
+	if e.Match {
+		t.Error("Match = true for a hash signed with another key")
+	}
+	if e.Payload != "INV-110.00Paid<integration key>" {
+		t.Errorf("Payload = %q", e.Payload)
+	}
+	if e.Expected != signature.Sign(fields, key) || e.Received == e.Expected {
+		t.Errorf("Expected = %s, Received = %s", e.Expected, e.Received)
+	}
+
+	out := e.String()
+	if strings.Contains(out, key) {
+		t.Error("String() leaks the integration key")
+	}
+	if !strings.Contains(out, `1. reference = "INV-1"`) || !strings.Contains(out, "does not match") {
+		t.Errorf("String() = %s", out)
+	}
+}
@@ -0,0 +1,76 @@
+package paynow
+
+import (
+	"context"
+
+	"github.com/IamTyrone/paynow-go/internal/hash"
+)
+
+// Signer computes the hash sent with every request to Paynow. Values are the
+// request's field values in the order they are sent.
+type Signer interface {
+	Sign(ctx context.Context, values []string) (string, error)
+}
+
+// Verifier checks the hash on a response or status update from Paynow against
+// its field values, in the order they were received. It returns an error
+// matching ErrHashMismatch if the hash does not match, and any other error if
+// it could not check.
+type Verifier interface {
+	Verify(ctx context.Context, values []string, hash string) error
+}
+
+// SignVerifier both signs requests and verifies responses. Supply one with
+// WithSigner to keep the integration key out of the process, for example in a
+// signing sidecar reached with NewSocketSigner.
+type SignVerifier interface {
+	Signer
+	Verifier
+}
+
+// WithSigner makes the Client sign requests and verify responses with s
+// instead of its integration key, which may then be empty. Keys passed to
+// WithPreviousKeys are still tried locally when s reports a mismatch.
+func WithSigner(s SignVerifier) Option {
+	return func(c *Client) {
+		if s != nil {
+			c.signer = s
+		}
+	}
+}
+
+// KeySigner is the default SignVerifier. It implements Paynow's SHA-512 scheme
+// with the integration key from Keys.
+type KeySigner struct {
+	Keys KeyProvider
+}
+
+// Sign implements Signer.
+func (s KeySigner) Sign(ctx context.Context, values []string) (string, error) {
+	key, err := integrationKeyFrom(ctx, s.Keys)
+	if err != nil {
+		return "", err
+	}
+	return hash.Make(values, key), nil
+}
+
+// Verify implements Verifier.
+func (s KeySigner) Verify(ctx context.Context, values []string, candidate string) error {
+	key, err := integrationKeyFrom(ctx, s.Keys)
+	if err != nil {
+		return err
+	}
+	if !hash.Equal(candidate, values, key) {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// signVerifier returns the Client's SignVerifier: the one set with WithSigner,
+// or a KeySigner using its KeyProvider.
+func (c *Client) signVerifier() SignVerifier {
+	if c.signer != nil {
+		return c.signer
+	}
+	return KeySigner{Keys: c.keys}
+}
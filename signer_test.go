@@ -0,0 +1,67 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+// startSigner runs a signing service holding key and returns its address.
+func startSigner(t *testing.T, key string) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() { _ = paynow.ServeSigner(l, paynow.KeySigner{Keys: paynow.StaticKey(key)}) }()
+	return l.Addr().String()
+}
+
+func TestSocketSigner_SignsAndVerifies(t *testing.T) {
+	doer := &mockDoer{response: signResponse(testKey, field{"status", "Ok"}, field{"pollurl", "p"})}
+	signer := paynow.NewSocketSigner("tcp", startSigner(t, testKey))
+	client := paynow.New("12345", "", paynow.WithSigner(signer), paynow.WithHTTPClient(doer))
+
+	if _, err := client.Send(context.Background(), paidPayment()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := client.ProcessStatusUpdate(statusBody(testKey, "Paid")); err != nil {
+		t.Errorf("ProcessStatusUpdate() error = %v", err)
+	}
+	if _, err := client.ProcessStatusUpdate(statusBody(oldKey, "Paid")); !errors.Is(err, paynow.ErrHashMismatch) {
+		t.Errorf("ProcessStatusUpdate() with wrong key error = %v, want ErrHashMismatch", err)
+	}
+}
+
+func TestSocketSigner_PreviousKeysStillTried(t *testing.T) {
+	signer := paynow.NewSocketSigner("tcp", startSigner(t, testKey))
+	client := paynow.New("12345", "", paynow.WithSigner(signer), paynow.WithPreviousKeys(oldKey))
+
+	resp, err := client.ProcessStatusUpdate(statusBody(oldKey, "Paid"))
+	if err != nil {
+		t.Fatalf("ProcessStatusUpdate() error = %v", err)
+	}
+	if resp.KeyIndex != 1 {
+		t.Errorf("KeyIndex = %d, want 1", resp.KeyIndex)
+	}
+}
+
+func TestSocketSigner_Errors(t *testing.T) {
+	// The service has no key, so it reports an error rather than a mismatch.
+	signer := paynow.NewSocketSigner("tcp", startSigner(t, ""))
+	if _, err := signer.Sign(context.Background(), []string{"a"}); err == nil {
+		t.Error("Sign() error = nil, want the service's error")
+	}
+	if err := signer.Verify(context.Background(), []string{"a"}, "ABC"); err == nil || errors.Is(err, paynow.ErrHashMismatch) {
+		t.Errorf("Verify() error = %v, want a service error", err)
+	}
+
+	unreachable := paynow.NewSocketSigner("unix", "/nonexistent/paynow-signer.sock")
+	if _, err := unreachable.Sign(context.Background(), []string{"a"}); err == nil {
+		t.Error("Sign() with no service error = nil")
+	}
+}
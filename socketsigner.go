@@ -0,0 +1,141 @@
+package paynow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// signerConnTimeout bounds how long ServeSigner waits for a client to send its
+// request, and to read the reply, so idle connections do not pile up.
+const signerConnTimeout = 10 * time.Second
+
+// signerRequest is a single request to a signing service. Op is "sign" or
+// "verify"; Hash is only set for "verify".
+type signerRequest struct {
+	Op     string   `json:"op"`
+	Values []string `json:"values"`
+	Hash   string   `json:"hash,omitempty"`
+}
+
+// signerReply answers a signerRequest. Error is set if the service could not
+// sign or verify.
+type signerReply struct {
+	Hash  string `json:"hash,omitempty"`
+	Match bool   `json:"match,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// SocketSigner is a SignVerifier that delegates to a signing service over a
+// local socket, such as a sidecar holding the integration key. Each call opens
+// a connection, writes one JSON request and reads one JSON reply; ServeSigner
+// implements the other end. Create one with NewSocketSigner.
+type SocketSigner struct {
+	network string
+	address string
+	dialer  net.Dialer
+}
+
+// NewSocketSigner returns a SocketSigner that connects to address on network,
+// for example ("unix", "/run/paynow-signer.sock") or ("tcp", "127.0.0.1:7070").
+func NewSocketSigner(network, address string) *SocketSigner {
+	return &SocketSigner{network: network, address: address}
+}
+
+// Sign implements Signer.
+func (s *SocketSigner) Sign(ctx context.Context, values []string) (string, error) {
+	reply, err := s.call(ctx, signerRequest{Op: "sign", Values: values})
+	if err != nil {
+		return "", err
+	}
+	return reply.Hash, nil
+}
+
+// Verify implements Verifier.
+func (s *SocketSigner) Verify(ctx context.Context, values []string, hash string) error {
+	reply, err := s.call(ctx, signerRequest{Op: "verify", Values: values, Hash: hash})
+	if err != nil {
+		return err
+	}
+	if !reply.Match {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// call sends req to the signing service and returns its reply.
+func (s *SocketSigner) call(ctx context.Context, req signerRequest) (*signerReply, error) {
+	conn, err := s.dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("paynow: failed to reach signer: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("paynow: failed to send to signer: %w", err)
+	}
+	var reply signerReply
+	if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+		return nil, fmt.Errorf("paynow: failed to read from signer: %w", err)
+	}
+	if reply.Error != "" {
+		return nil, fmt.Errorf("paynow: signer: %s", reply.Error)
+	}
+	return &reply, nil
+}
+
+// ServeSigner answers SocketSigner requests on l using s, typically a
+// KeySigner, so the integration key stays in the signing process. It serves
+// each connection in its own goroutine, closing connections that do not send
+// a request within ten seconds, and returns the error from l.Accept once l is
+// closed.
+func ServeSigner(l net.Listener, s SignVerifier) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveSignerConn(conn, s)
+	}
+}
+
+// serveSignerConn answers the single request on conn.
+func serveSignerConn(conn net.Conn, s SignVerifier) {
+	defer func() { _ = conn.Close() }()
+
+	if err := conn.SetDeadline(time.Now().Add(signerConnTimeout)); err != nil {
+		return
+	}
+	var req signerRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	var reply signerReply
+	switch req.Op {
+	case "sign":
+		h, err := s.Sign(ctx, req.Values)
+		if err != nil {
+			reply.Error = err.Error()
+		}
+		reply.Hash = h
+	case "verify":
+		err := s.Verify(ctx, req.Values, req.Hash)
+		switch {
+		case err == nil:
+			reply.Match = true
+		case !errors.Is(err, ErrHashMismatch):
+			reply.Error = err.Error()
+		}
+	default:
+		reply.Error = fmt.Sprintf("unknown operation %q", req.Op)
+	}
+	_ = json.NewEncoder(conn).Encode(reply)
+}
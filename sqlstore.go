@@ -0,0 +1,369 @@
+package paynow
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the versioned schema migrations for each SQLDialect, in
+// migrations/<dialect>/<version>_<description>.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// SQLDialect selects the flavour of SQL an SQLStore speaks. The SDK does not
+// import any database driver; open the *sql.DB with the driver of your choice
+// and pass the matching dialect.
+type SQLDialect string
+
+const (
+	// DialectSQLite is for SQLite and SQLite-compatible databases. Queries use
+	// "?" placeholders.
+	DialectSQLite SQLDialect = "sqlite"
+
+	// DialectPostgres is for PostgreSQL and PostgreSQL-compatible databases.
+	// Queries use "$1"-style placeholders.
+	DialectPostgres SQLDialect = "postgres"
+)
+
+// Queries issued by SQLStore, written with "?" placeholders and rebound for the
+// dialect in use.
+const (
+	sqlCreateMigrationsTable = `CREATE TABLE IF NOT EXISTS paynow_schema_migrations (version BIGINT NOT NULL PRIMARY KEY, applied_at BIGINT NOT NULL)`
+	sqlSelectMigrations      = `SELECT version FROM paynow_schema_migrations`
+	sqlInsertMigration       = `INSERT INTO paynow_schema_migrations (version, applied_at) VALUES (?, ?)`
+
+	sqlTransactionColumns = `reference, paynow_reference, poll_url, amount_cents, method, status, pending, version, created_at, updated_at, items`
+
+	sqlInsertTransaction = `INSERT INTO paynow_transactions (` + sqlTransactionColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (reference) DO NOTHING`
+	sqlUpdateStatus      = `UPDATE paynow_transactions SET status = ?, pending = ?, paynow_reference = CASE WHEN ? = '' THEN paynow_reference ELSE ? END, version = version + 1, updated_at = ? WHERE reference = ?`
+	sqlVersionPredicate  = ` AND version = ?`
+
+	sqlSelectByReference = `SELECT ` + sqlTransactionColumns + ` FROM paynow_transactions WHERE reference = ?`
+	sqlSelectByPollURL   = `SELECT ` + sqlTransactionColumns + ` FROM paynow_transactions WHERE poll_url = ?`
+	sqlSelectPending     = `SELECT ` + sqlTransactionColumns + ` FROM paynow_transactions WHERE pending = 1 ORDER BY created_at, reference`
+)
+
+// SQLStore is a VersionedStore backed by a database/sql database. Call Migrate
+// once at startup to create or upgrade the schema before using it.
+type SQLStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+	now     func() time.Time
+}
+
+// NewSQLStore returns an SQLStore that uses db, speaking the given dialect.
+func NewSQLStore(db *sql.DB, dialect SQLDialect) *SQLStore {
+	return &SQLStore{db: db, dialect: dialect, now: time.Now}
+}
+
+// migration is a single versioned schema change.
+type migration struct {
+	version    int64
+	statements []string
+}
+
+// migrations returns the embedded migrations for the store's dialect, ordered
+// by version.
+func (s *SQLStore) migrations() ([]migration, error) {
+	dir := path.Join("migrations", string(s.dialect))
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("paynow: unsupported SQL dialect %q", s.dialect)
+	}
+
+	out := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, _, ok := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil || !strings.HasSuffix(name, ".sql") {
+			return nil, fmt.Errorf("paynow: malformed migration name %q", name)
+		}
+
+		script, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, migration{version: version, statements: splitStatements(string(script))})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].version < out[j].version })
+	return out, nil
+}
+
+// splitStatements splits a migration script into individual statements,
+// dropping "--" comment lines, since not every driver accepts several
+// statements in one Exec.
+func splitStatements(script string) []string {
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	var statements []string
+	for _, stmt := range strings.Split(b.String(), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// Migrate brings the schema up to date, applying each embedded migration that
+// has not yet been recorded in the paynow_schema_migrations table. Every
+// migration runs in its own database transaction.
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	migrations, err := s.migrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, sqlCreateMigrationsTable); err != nil {
+		return fmt.Errorf("paynow: failed to create migrations table: %w", err)
+	}
+
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := s.apply(ctx, m); err != nil {
+			return fmt.Errorf("paynow: migration %d failed: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+// appliedMigrations returns the set of migration versions already applied.
+func (s *SQLStore) appliedMigrations(ctx context.Context) (map[int64]bool, error) {
+	rows, err := s.db.QueryContext(ctx, sqlSelectMigrations)
+	if err != nil {
+		return nil, fmt.Errorf("paynow: failed to read migrations: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("paynow: failed to read migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// apply runs a single migration and records it, atomically.
+func (s *SQLStore) apply(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range m.statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, s.rebind(sqlInsertMigration), m.version, s.now().UnixNano()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rebind rewrites "?" placeholders into the dialect's placeholder syntax.
+func (s *SQLStore) rebind(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Save implements Store.
+func (s *SQLStore) Save(ctx context.Context, tx *Transaction) error {
+	now := s.now()
+	created := tx.CreatedAt
+	if created.IsZero() {
+		created = now
+	}
+	updated := tx.UpdatedAt
+	if updated.IsZero() {
+		updated = created
+	}
+
+	items, err := encodeItems(tx.Items)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(ctx, s.rebind(sqlInsertTransaction),
+		tx.Reference, tx.PaynowReference, tx.PollURL, toCents(tx.Amount), string(tx.Method),
+		string(tx.Status), pendingFlag(tx.Status), int64(1), created.UnixNano(), updated.UnixNano(), items,
+	)
+	if err != nil {
+		return fmt.Errorf("paynow: failed to save transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrDuplicateReference
+	}
+	return nil
+}
+
+// UpdateStatus implements Store.
+func (s *SQLStore) UpdateStatus(ctx context.Context, reference string, status TransactionStatus, paynowReference string) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(sqlUpdateStatus),
+		string(status), pendingFlag(status), paynowReference, paynowReference, s.now().UnixNano(), reference,
+	)
+	if err != nil {
+		return fmt.Errorf("paynow: failed to update transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// CompareAndUpdateStatus implements VersionedStore. The version check and the
+// update happen in a single UPDATE statement, so no locks are held.
+func (s *SQLStore) CompareAndUpdateStatus(ctx context.Context, reference string, version int64, status TransactionStatus, paynowReference string) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(sqlUpdateStatus+sqlVersionPredicate),
+		string(status), pendingFlag(status), paynowReference, paynowReference, s.now().UnixNano(), reference, version,
+	)
+	if err != nil {
+		return fmt.Errorf("paynow: failed to update transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// Distinguish a missing transaction from one that has moved on.
+		if _, err := s.FindByReference(ctx, reference); err != nil {
+			return err
+		}
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// FindByReference implements Store.
+func (s *SQLStore) FindByReference(ctx context.Context, reference string) (*Transaction, error) {
+	return s.findOne(ctx, sqlSelectByReference, reference)
+}
+
+// FindByPollURL implements Store.
+func (s *SQLStore) FindByPollURL(ctx context.Context, pollURL string) (*Transaction, error) {
+	return s.findOne(ctx, sqlSelectByPollURL, pollURL)
+}
+
+// findOne runs a query expected to return at most one transaction.
+func (s *SQLStore) findOne(ctx context.Context, query string, arg string) (*Transaction, error) {
+	tx, err := scanTransaction(s.db.QueryRowContext(ctx, s.rebind(query), arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("paynow: failed to load transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// ListPending implements Store.
+func (s *SQLStore) ListPending(ctx context.Context) ([]*Transaction, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(sqlSelectPending))
+	if err != nil {
+		return nil, fmt.Errorf("paynow: failed to list transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var pending []*Transaction
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("paynow: failed to list transactions: %w", err)
+		}
+		pending = append(pending, tx)
+	}
+	return pending, rows.Err()
+}
+
+// scanner is the Scan method shared by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTransaction reads a row selected with sqlTransactionColumns.
+func scanTransaction(row scanner) (*Transaction, error) {
+	var (
+		tx                 Transaction
+		cents, pending     int64
+		method, status     string
+		created, updatedAt int64
+		items              string
+	)
+	err := row.Scan(&tx.Reference, &tx.PaynowReference, &tx.PollURL, &cents, &method,
+		&status, &pending, &tx.Version, &created, &updatedAt, &items)
+	if err != nil {
+		return nil, err
+	}
+	if items != "" {
+		if err := json.Unmarshal([]byte(items), &tx.Items); err != nil {
+			return nil, fmt.Errorf("corrupt items for %q: %w", tx.Reference, err)
+		}
+	}
+
+	tx.Amount = fromCents(cents)
+	tx.Method = PaymentMethod(method)
+	tx.Status = TransactionStatus(status)
+	tx.CreatedAt = time.Unix(0, created)
+	tx.UpdatedAt = time.Unix(0, updatedAt)
+	return &tx, nil
+}
+
+// encodeItems renders cart items for the items column. No items is stored as
+// an empty string.
+func encodeItems(items []CartItem) (string, error) {
+	if len(items) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return "", fmt.Errorf("paynow: failed to encode items: %w", err)
+	}
+	return string(raw), nil
+}
+
+// pendingFlag is the value of the pending column for status.
+func pendingFlag(status TransactionStatus) int64 {
+	if status.IsPending() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,269 @@
+package paynow_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+// memSQL is a minimal in-memory database/sql driver that understands exactly
+// the statements SQLStore issues. The DSN is "<dialect>/<name>"; each name is a
+// separate database, and queries are checked for the dialect's placeholders.
+type memSQL struct {
+	mu  sync.Mutex
+	dbs map[string]*memDB
+}
+
+type memDB struct {
+	mu         sync.Mutex
+	dialect    string
+	migrations map[int64]bool
+	created    bool
+	indexes    []string
+	rows       map[string][]driver.Value
+}
+
+func init() {
+	sql.Register("paynow-memsql", &memSQL{dbs: make(map[string]*memDB)})
+}
+
+func (d *memSQL) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	db, ok := d.dbs[dsn]
+	if !ok {
+		dialect, _, _ := strings.Cut(dsn, "/")
+		db = &memDB{dialect: dialect, migrations: make(map[int64]bool), rows: make(map[string][]driver.Value)}
+		d.dbs[dsn] = db
+	}
+	return &memConn{db: db}, nil
+}
+
+type memConn struct{ db *memDB }
+
+func (c *memConn) Prepare(query string) (driver.Stmt, error) {
+	return &memStmt{db: c.db, query: query}, nil
+}
+func (c *memConn) Close() error              { return nil }
+func (c *memConn) Begin() (driver.Tx, error) { return memTx{}, nil }
+
+type memTx struct{}
+
+func (memTx) Commit() error   { return nil }
+func (memTx) Rollback() error { return nil }
+
+type memStmt struct {
+	db    *memDB
+	query string
+}
+
+func (s *memStmt) Close() error  { return nil }
+func (s *memStmt) NumInput() int { return -1 }
+
+func (s *memStmt) checkPlaceholders(args []driver.Value) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if s.db.dialect == "postgres" && (strings.Contains(s.query, "?") || !strings.Contains(s.query, "$1")) {
+		return fmt.Errorf("memsql: expected $n placeholders in %q", s.query)
+	}
+	if s.db.dialect == "sqlite" && strings.Contains(s.query, "$1") {
+		return fmt.Errorf("memsql: expected ? placeholders in %q", s.query)
+	}
+	return nil
+}
+
+func (s *memStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.checkPlaceholders(args); err != nil {
+		return nil, err
+	}
+	db := s.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch q := s.query; {
+	case strings.HasPrefix(q, "CREATE TABLE IF NOT EXISTS paynow_schema_migrations"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(q, "INSERT INTO paynow_schema_migrations"):
+		db.migrations[args[0].(int64)] = true
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(q, "CREATE TABLE paynow_transactions"):
+		if db.created {
+			return nil, errors.New("memsql: table paynow_transactions already exists")
+		}
+		db.created = true
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(q, "ALTER TABLE paynow_transactions ADD COLUMN items"):
+		for ref, row := range db.rows {
+			db.rows[ref] = append(row, "")
+		}
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(q, "CREATE INDEX"):
+		db.indexes = append(db.indexes, strings.Fields(q)[2])
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(q, "INSERT INTO paynow_transactions"):
+		ref := args[0].(string)
+		if _, ok := db.rows[ref]; ok {
+			return driver.RowsAffected(0), nil
+		}
+		db.rows[ref] = append([]driver.Value(nil), args...)
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(q, "UPDATE paynow_transactions"):
+		row, ok := db.rows[args[5].(string)]
+		if !ok || (len(args) > 6 && row[7].(int64) != args[6].(int64)) {
+			return driver.RowsAffected(0), nil
+		}
+		row[5], row[6], row[9] = args[0], args[1], args[4]
+		if args[2].(string) != "" {
+			row[1] = args[3]
+		}
+		row[7] = row[7].(int64) + 1
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("memsql: unsupported statement %q", s.query)
+}
+
+func (s *memStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.checkPlaceholders(args); err != nil {
+		return nil, err
+	}
+	db := s.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var out [][]driver.Value
+	switch q := s.query; {
+	case strings.HasPrefix(q, "SELECT version FROM paynow_schema_migrations"):
+		for v := range db.migrations {
+			out = append(out, []driver.Value{v})
+		}
+		return &memRows{cols: []string{"version"}, rows: out}, nil
+	case strings.Contains(q, "WHERE reference ="):
+		if row, ok := db.rows[args[0].(string)]; ok {
+			out = append(out, row)
+		}
+	case strings.Contains(q, "WHERE poll_url ="):
+		for _, row := range db.rows {
+			if row[2] == args[0] {
+				out = append(out, row)
+			}
+		}
+	case strings.Contains(q, "WHERE pending = 1"):
+		for _, row := range db.rows {
+			if row[6].(int64) == 1 {
+				out = append(out, row)
+			}
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i][8].(int64) < out[j][8].(int64) })
+	default:
+		return nil, fmt.Errorf("memsql: unsupported query %q", s.query)
+	}
+
+	copied := make([][]driver.Value, len(out))
+	for i, row := range out {
+		copied[i] = append([]driver.Value(nil), row...)
+	}
+	return &memRows{cols: make([]string, 11), rows: copied}, nil
+}
+
+type memRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *memRows) Columns() []string { return r.cols }
+func (r *memRows) Close() error      { return nil }
+func (r *memRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openSQLStore(t *testing.T, dialect paynow.SQLDialect) *paynow.SQLStore {
+	t.Helper()
+	db, err := sql.Open("paynow-memsql", string(dialect)+"/"+t.Name())
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := paynow.NewSQLStore(db, dialect)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return store
+}
+
+func TestSQLStore(t *testing.T) {
+	for _, dialect := range []paynow.SQLDialect{paynow.DialectSQLite, paynow.DialectPostgres} {
+		dialect := dialect
+		t.Run(string(dialect), func(t *testing.T) {
+			testStoreBehaviour(t, openSQLStore(t, dialect))
+		})
+	}
+}
+
+func TestSQLStore_MigrateIsIdempotent(t *testing.T) {
+	store := openSQLStore(t, paynow.DialectSQLite)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Errorf("second Migrate() error = %v, want already-applied migrations skipped", err)
+	}
+}
+
+func TestSQLStore_UnknownDialect(t *testing.T) {
+	db, _ := sql.Open("paynow-memsql", "mysql/"+t.Name())
+	defer func() { _ = db.Close() }()
+
+	if err := paynow.NewSQLStore(db, "mysql").Migrate(context.Background()); err == nil {
+		t.Error("Migrate() with an unknown dialect should fail")
+	}
+}
+
+func TestSQLStore_OptimisticConcurrency(t *testing.T) {
+	testVersionedStore(t, openSQLStore(t, paynow.DialectPostgres))
+}
+
+func TestMemoryStore_OptimisticConcurrency(t *testing.T) {
+	testVersionedStore(t, paynow.NewMemoryStore())
+}
+
+func testVersionedStore(t *testing.T, store paynow.VersionedStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.Save(ctx, &paynow.Transaction{Reference: "INV-1", Amount: 10, Status: paynow.StatusSent}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	tx, _ := store.FindByReference(ctx, "INV-1")
+	if tx.Version != 1 {
+		t.Fatalf("Version = %d, want 1", tx.Version)
+	}
+
+	if err := store.CompareAndUpdateStatus(ctx, "INV-1", tx.Version, paynow.StatusPaid, "PN-1"); err != nil {
+		t.Fatalf("CompareAndUpdateStatus() error = %v", err)
+	}
+	if err := store.CompareAndUpdateStatus(ctx, "INV-1", tx.Version, paynow.StatusCancelled, ""); !errors.Is(err, paynow.ErrVersionConflict) {
+		t.Errorf("CompareAndUpdateStatus(stale) error = %v, want ErrVersionConflict", err)
+	}
+	if err := store.CompareAndUpdateStatus(ctx, "missing", 1, paynow.StatusPaid, ""); !errors.Is(err, paynow.ErrTransactionNotFound) {
+		t.Errorf("CompareAndUpdateStatus(missing) error = %v, want ErrTransactionNotFound", err)
+	}
+
+	tx, _ = store.FindByReference(ctx, "INV-1")
+	if tx.Status != paynow.StatusPaid || tx.Version != 2 || tx.Amount != 10 {
+		t.Errorf("transaction = %+v, want Paid at version 2", tx)
+	}
+}
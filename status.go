@@ -59,3 +59,16 @@ func (s TransactionStatus) IsPending() bool {
 func (s TransactionStatus) IsFailed() bool {
 	return s.Is(StatusCancelled) || s.Is(StatusFailed) || s.Is(StatusDisputed)
 }
+
+// MarshalText implements encoding.TextMarshaler. Known statuses are written in
+// Paynow's casing, so "PAID" and "paid" both encode as "Paid".
+func (s TransactionStatus) MarshalText() ([]byte, error) {
+	return []byte(s.canonical()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, canonicalising known
+// statuses as MarshalText does. Unknown statuses are kept, trimmed.
+func (s *TransactionStatus) UnmarshalText(text []byte) error {
+	*s = TransactionStatus(text).canonical()
+	return nil
+}
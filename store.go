@@ -0,0 +1,221 @@
+package paynow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transaction is the merchant-side record of a transaction initiated with
+// Paynow. A Store persists these so the poll URL and latest status survive
+// restarts.
+type Transaction struct {
+	// Reference is the merchant's reference for the transaction. It is the
+	// primary key in every Store.
+	Reference string `json:"reference"`
+
+	// PaynowReference is Paynow's own reference, known once a status update or
+	// poll has been received.
+	PaynowReference string `json:"paynow_reference,omitempty"`
+
+	// PollURL is the URL returned by Paynow for checking the transaction status.
+	PollURL string `json:"poll_url"`
+
+	// Amount is the total that was sent to Paynow.
+	Amount float64 `json:"amount"`
+
+	// Method is the mobile money method for express-checkout transactions, and
+	// empty for web transactions.
+	Method PaymentMethod `json:"method,omitempty"`
+
+	// Items are the cart items that were sent, including their local SKU,
+	// description, category and metadata.
+	Items []CartItem `json:"items,omitempty"`
+
+	// Status is the most recently recorded status.
+	Status TransactionStatus `json:"status"`
+
+	// CreatedAt is when the transaction was first saved.
+	CreatedAt time.Time `json:"created_at"`
+
+	// UpdatedAt is when the transaction was last saved or updated.
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Version starts at 1 when the transaction is saved and is incremented by
+	// every status update. It backs optimistic concurrency in VersionedStore.
+	Version int64 `json:"version"`
+}
+
+// clone returns a copy of the transaction that shares no memory with it.
+func (tx *Transaction) clone() *Transaction {
+	out := *tx
+	out.Items = cloneItems(tx.Items)
+	return &out
+}
+
+// Store persists initiated transactions and their status. Implementations must
+// be safe for concurrent use. Lookups return ErrTransactionNotFound when there
+// is no matching transaction.
+//
+// Attach a Store to a Client with WithStore to have initiated transactions
+// saved, and polled or posted status updates recorded, automatically.
+type Store interface {
+	// Save records a newly initiated transaction. It returns
+	// ErrDuplicateReference if a transaction with the same reference exists.
+	Save(ctx context.Context, tx *Transaction) error
+
+	// UpdateStatus records a new status, and Paynow's reference when it is not
+	// empty, for the transaction with the given merchant reference.
+	UpdateStatus(ctx context.Context, reference string, status TransactionStatus, paynowReference string) error
+
+	// FindByReference returns the transaction with the given merchant reference.
+	FindByReference(ctx context.Context, reference string) (*Transaction, error)
+
+	// FindByPollURL returns the transaction with the given poll URL.
+	FindByPollURL(ctx context.Context, pollURL string) (*Transaction, error)
+
+	// ListPending returns every transaction whose status is still pending,
+	// oldest first.
+	ListPending(ctx context.Context) ([]*Transaction, error)
+}
+
+// VersionedStore is a Store that supports optimistic concurrency on status
+// updates, so a caller that read a transaction can update it without silently
+// overwriting a change made in the meantime. The Client uses it in preference
+// to UpdateStatus when recording an observed status.
+type VersionedStore interface {
+	Store
+
+	// CompareAndUpdateStatus behaves like UpdateStatus, but only applies the
+	// update if the stored transaction is still at the given version. It returns
+	// ErrVersionConflict if the transaction has been updated since.
+	CompareAndUpdateStatus(ctx context.Context, reference string, version int64, status TransactionStatus, paynowReference string) error
+}
+
+// Store errors.
+var (
+	// ErrTransactionNotFound is returned by a Store when no transaction matches
+	// a lookup or update.
+	ErrTransactionNotFound = errors.New("paynow: transaction not found")
+
+	// ErrDuplicateReference is returned by Store.Save when a transaction with
+	// the same reference has already been saved.
+	ErrDuplicateReference = errors.New("paynow: a transaction with this reference already exists")
+
+	// ErrVersionConflict is returned by VersionedStore.CompareAndUpdateStatus
+	// when the transaction was modified after it was read.
+	ErrVersionConflict = errors.New("paynow: transaction was updated concurrently")
+)
+
+// maxRecordAttempts bounds how often recordStatus retries after losing an
+// optimistic concurrency race.
+const maxRecordAttempts = 3
+
+// WithStore sets the Store the Client records transactions in. Successful Send
+// and SendMobile calls save the initiated transaction, and PollTransaction and
+// ProcessStatusUpdate record the status they observe, as long as the Client's
+// StatusMachine allows the change.
+func WithStore(store Store) Option {
+	return func(c *Client) { c.store = store }
+}
+
+// WithStatusMachine sets the StatusMachine used to validate status changes
+// before they are recorded in the Client's Store. By default NewStatusMachine
+// is used.
+func WithStatusMachine(m *StatusMachine) Option {
+	return func(c *Client) {
+		if m != nil {
+			c.statuses = m
+		}
+	}
+}
+
+// recordInitiated saves a successfully initiated transaction to the Client's
+// store, if one is configured.
+func (c *Client) recordInitiated(ctx context.Context, payment *Payment, method PaymentMethod, resp *InitResponse) error {
+	if c.store == nil {
+		return nil
+	}
+
+	tx := &Transaction{
+		Reference: payment.Reference,
+		PollURL:   resp.PollURL,
+		Amount:    payment.Total(),
+		Method:    method,
+		Items:     payment.Items(),
+		Status:    StatusSent,
+	}
+	if err := c.store.Save(ctx, tx); err != nil {
+		return fmt.Errorf("paynow: failed to record transaction %q: %w", payment.Reference, err)
+	}
+	return nil
+}
+
+// recordStatus records an observed status in the Client's store, if one is
+// configured. The transaction is located by merchant reference, falling back to
+// the poll URL when the response does not carry a reference. Responses without
+// a status, and for transactions the store does not know (such as ones
+// initiated before the Store was added), have nothing to record and are
+// ignored. Status changes the Client's StatusMachine rejects are not recorded,
+// and are reported as a *TransitionError.
+func (c *Client) recordStatus(ctx context.Context, pollURL string, resp *StatusResponse) error {
+	if c.store == nil || strings.TrimSpace(string(resp.Status)) == "" {
+		return nil
+	}
+
+	var err error
+	for attempt := 0; attempt < maxRecordAttempts; attempt++ {
+		if err = c.tryRecordStatus(ctx, pollURL, resp); !errors.Is(err, ErrVersionConflict) {
+			break
+		}
+	}
+	if errors.Is(err, ErrTransactionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("paynow: failed to record status: %w", err)
+	}
+	return nil
+}
+
+// tryRecordStatus makes a single attempt at recordStatus. With a VersionedStore
+// it returns ErrVersionConflict if the transaction changed after it was read.
+func (c *Client) tryRecordStatus(ctx context.Context, pollURL string, resp *StatusResponse) error {
+	tx, err := c.findTransaction(ctx, pollURL, resp)
+	if err != nil {
+		return err
+	}
+
+	next, err := c.statuses.merge(tx.Status, resp)
+	if err != nil {
+		return err
+	}
+	if next == tx.Status && (resp.PaynowReference == "" || resp.PaynowReference == tx.PaynowReference) {
+		return nil
+	}
+
+	if versioned, ok := c.store.(VersionedStore); ok {
+		err = versioned.CompareAndUpdateStatus(ctx, tx.Reference, tx.Version, next, resp.PaynowReference)
+	} else {
+		err = c.store.UpdateStatus(ctx, tx.Reference, next, resp.PaynowReference)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.statuses.notify(StatusTransition{From: tx.Status.canonical(), To: next})
+	return nil
+}
+
+// findTransaction looks up the stored transaction a status response is for.
+func (c *Client) findTransaction(ctx context.Context, pollURL string, resp *StatusResponse) (*Transaction, error) {
+	if resp.Reference != "" {
+		return c.store.FindByReference(ctx, resp.Reference)
+	}
+	if pollURL == "" {
+		pollURL = resp.PollURL
+	}
+	return c.store.FindByPollURL(ctx, pollURL)
+}
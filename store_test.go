@@ -0,0 +1,194 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func testStoreBehaviour(t *testing.T, store paynow.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	tx := &paynow.Transaction{
+		Reference: "INV-1",
+		PollURL:   "https://www.paynow.co.zw/interface/poll/1",
+		Amount:    10.00,
+		Status:    paynow.StatusSent,
+		Items: []paynow.CartItem{
+			{Title: "Widget", Amount: 10.00, Quantity: 1, SKU: "W-1", Metadata: map[string]string{"product_id": "42"}},
+		},
+	}
+	if err := store.Save(ctx, tx); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Save(ctx, tx); !errors.Is(err, paynow.ErrDuplicateReference) {
+		t.Errorf("Save(duplicate) error = %v, want ErrDuplicateReference", err)
+	}
+
+	pending, err := store.ListPending(ctx)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("ListPending() = %d transactions, %v; want 1", len(pending), err)
+	}
+
+	if err := store.UpdateStatus(ctx, "INV-1", paynow.StatusPaid, "PN-1"); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	got, err := store.FindByPollURL(ctx, tx.PollURL)
+	if err != nil {
+		t.Fatalf("FindByPollURL() error = %v", err)
+	}
+	if got.Status != paynow.StatusPaid || got.PaynowReference != "PN-1" {
+		t.Errorf("FindByPollURL() = %+v, want a paid transaction with PN-1", got)
+	}
+	if len(got.Items) != 1 || got.Items[0].SKU != "W-1" || got.Items[0].Metadata["product_id"] != "42" {
+		t.Errorf("Items = %+v, want the saved SKU and metadata", got.Items)
+	}
+	if got.CreatedAt.IsZero() || got.UpdatedAt.Before(got.CreatedAt) {
+		t.Errorf("timestamps not stamped: created %v, updated %v", got.CreatedAt, got.UpdatedAt)
+	}
+
+	if pending, _ := store.ListPending(ctx); len(pending) != 0 {
+		t.Errorf("ListPending() = %d transactions after payment, want 0", len(pending))
+	}
+	if _, err := store.FindByReference(ctx, "missing"); !errors.Is(err, paynow.ErrTransactionNotFound) {
+		t.Errorf("FindByReference(missing) error = %v, want ErrTransactionNotFound", err)
+	}
+	if err := store.UpdateStatus(ctx, "missing", paynow.StatusPaid, ""); !errors.Is(err, paynow.ErrTransactionNotFound) {
+		t.Errorf("UpdateStatus(missing) error = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStoreBehaviour(t, paynow.NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+
+	store, err := paynow.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v", err)
+	}
+	testStoreBehaviour(t, store)
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reopened, err := paynow.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore(reopen) error = %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+
+	got, err := reopened.FindByReference(context.Background(), "INV-1")
+	if err != nil {
+		t.Fatalf("FindByReference() after reopen error = %v", err)
+	}
+	if got.Status != paynow.StatusPaid {
+		t.Errorf("Status after reopen = %q, want Paid", got.Status)
+	}
+}
+
+func TestFileStore_RecoversFromTruncatedAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+	ctx := context.Background()
+
+	store, err := paynow.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v", err)
+	}
+	_ = store.Save(ctx, &paynow.Transaction{Reference: "INV-1", Status: paynow.StatusSent})
+	_ = store.Close()
+
+	// Simulate a crash part-way through appending a second record.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"reference":"INV-2","sta`)
+	_ = f.Close()
+
+	store, err = paynow.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() after a partial write error = %v", err)
+	}
+	if _, err := store.FindByReference(ctx, "INV-1"); err != nil {
+		t.Errorf("FindByReference(INV-1) error = %v, want the complete record kept", err)
+	}
+	if err := store.Save(ctx, &paynow.Transaction{Reference: "INV-3", Status: paynow.StatusSent}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	_ = store.Close()
+
+	store, err = paynow.OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore() after repair error = %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	if _, err := store.FindByReference(ctx, "INV-3"); err != nil {
+		t.Errorf("FindByReference(INV-3) error = %v, want it appended after the repair", err)
+	}
+}
+
+func TestFileStore_ReportsCorruptionMidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+	content := "{\"reference\":\"INV-1\"}\nnot json\n{\"reference\":\"INV-2\"}\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := paynow.OpenFileStore(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("OpenFileStore() error = %v, want corruption reported at line 2", err)
+	}
+}
+
+func TestClient_RecordsInStore(t *testing.T) {
+	store := paynow.NewMemoryStore()
+	doer := &mockDoer{response: signResponse(testKey,
+		field{"status", "Ok"},
+		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
+	)}
+	client := paynow.New("12345", testKey, paynow.WithHTTPClient(doer), paynow.WithStore(store))
+	ctx := context.Background()
+
+	if _, err := client.SendMobile(ctx, paidPayment(), "0771234567", paynow.MethodEcocash); err != nil {
+		t.Fatalf("SendMobile() error = %v", err)
+	}
+	tx, err := store.FindByReference(ctx, "INV-1")
+	if err != nil {
+		t.Fatalf("transaction was not saved: %v", err)
+	}
+	if tx.Method != paynow.MethodEcocash || tx.Amount != 10.00 || !tx.Status.IsPending() {
+		t.Errorf("saved transaction = %+v", tx)
+	}
+
+	if _, err := client.ProcessStatusUpdate(paidStatusBody()); err != nil {
+		t.Fatalf("ProcessStatusUpdate() error = %v", err)
+	}
+	tx, _ = store.FindByReference(ctx, "INV-1")
+	if !tx.Status.IsPaid() || tx.PaynowReference != "PN-987" {
+		t.Errorf("status not recorded: %+v", tx)
+	}
+}
+
+func TestClient_IgnoresUnknownTransactions(t *testing.T) {
+	doer := &mockDoer{response: paidStatusBody()}
+	client := paynow.New("12345", testKey, paynow.WithHTTPClient(doer), paynow.WithStore(paynow.NewMemoryStore()))
+
+	if resp, err := client.ProcessStatusUpdate(paidStatusBody()); err != nil || !resp.Paid {
+		t.Errorf("ProcessStatusUpdate() = %+v, %v; want the paid response for a transaction initiated before the Store", resp, err)
+	}
+	if _, err := client.PollTransaction(context.Background(), "https://www.paynow.co.zw/interface/poll/1"); err != nil {
+		t.Errorf("PollTransaction() error = %v, want nil for an unknown transaction", err)
+	}
+
+	noStatus := signResponse(testKey, field{"reference", "INV-1"}, field{"amount", "10.00"})
+	if _, err := client.ProcessStatusUpdate(noStatus); err != nil {
+		t.Errorf("ProcessStatusUpdate() without a status error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,93 @@
+package paynow
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxInfoLength is the longest "additionalinfo" text, in characters, the SDK
+// sends to Paynow. Paynow truncates or rejects longer values, so Payment.Info
+// shortens anything longer, ending it with "...".
+const MaxInfoLength = 255
+
+// infoEllipsis marks text shortened to fit MaxInfoLength.
+const infoEllipsis = "..."
+
+// SummaryFormatter renders a payment's items as the "additionalinfo" text
+// Paynow shows the customer. Set one on Payment.Summary to replace
+// TitleSummary. Its output is sanitised and limited to MaxInfoLength.
+type SummaryFormatter func(items []CartItem) string
+
+// TitleSummary is the default SummaryFormatter. It lists every item title,
+// separated by commas: "T-shirt, Socks".
+func TitleSummary(items []CartItem) string {
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	return strings.Join(titles, ", ")
+}
+
+// CountedSummary is a SummaryFormatter that leads with the number of units and
+// shows quantities: "3 items: T-shirt x2, Socks".
+func CountedSummary(items []CartItem) string {
+	units := 0
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		units += item.units()
+		if item.units() > 1 {
+			lines = append(lines, fmt.Sprintf("%s x%d", item.Title, item.units()))
+		} else {
+			lines = append(lines, item.Title)
+		}
+	}
+
+	noun := "items"
+	if units == 1 {
+		noun = "item"
+	}
+	return fmt.Sprintf("%d %s: %s", units, noun, strings.Join(lines, ", "))
+}
+
+// sanitizeInfo makes text safe for Paynow to display with cleanInfo and limits
+// it to MaxInfoLength characters.
+func sanitizeInfo(text string) string {
+	return truncateInfo(cleanInfo(text))
+}
+
+// cleanInfo replaces control characters (including newlines and tabs) with
+// spaces, drops markup characters, collapses runs of whitespace and trims the
+// result.
+func cleanInfo(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range text {
+		switch {
+		case r == utf8.RuneError, r == '<', r == '>':
+			continue
+		case unicode.IsSpace(r) || unicode.IsControl(r):
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// truncateInfo shortens text to MaxInfoLength characters, ending it with
+// infoEllipsis when anything was cut.
+func truncateInfo(text string) string {
+	if utf8.RuneCountInString(text) <= MaxInfoLength {
+		return text
+	}
+
+	keep := MaxInfoLength - len(infoEllipsis)
+	runes := []rune(text)[:keep]
+	return strings.TrimRight(string(runes), " ,") + infoEllipsis
+}
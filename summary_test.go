@@ -0,0 +1,45 @@
+package paynow_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestPayment_CountedSummary(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "buyer@example.com").
+		Add("T-shirt", 10.00, 2).
+		Add("Socks", 3.00)
+	p.Summary = paynow.CountedSummary
+
+	if got, want := p.Info(), "3 items: T-shirt x2, Socks"; got != want {
+		t.Errorf("Info() = %q, want %q", got, want)
+	}
+}
+
+func TestPayment_InfoIsSanitised(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "buyer@example.com").
+		Add("  <b>Bold</b>\tmug\n", 1.00).
+		Add("Line\r\nbreak", 1.00)
+
+	if got, want := p.Info(), "bBold/b mug, Line break"; got != want {
+		t.Errorf("Info() = %q, want %q", got, want)
+	}
+}
+
+func TestPayment_InfoIsLimited(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "buyer@example.com")
+	for i := 0; i < 100; i++ {
+		p.Add("Ünïcode item", 1.00)
+	}
+
+	info := p.Info()
+	if n := utf8.RuneCountInString(info); n > paynow.MaxInfoLength {
+		t.Errorf("Info() is %d characters, want at most %d", n, paynow.MaxInfoLength)
+	}
+	if !strings.HasSuffix(info, "...") || !utf8.ValidString(info) {
+		t.Errorf("Info() = %q, want valid UTF-8 ending in an ellipsis", info)
+	}
+}
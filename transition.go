@@ -0,0 +1,155 @@
+package paynow
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidTransition is matched (via errors.Is) by every *TransitionError.
+var ErrInvalidTransition = errors.New("paynow: invalid status transition")
+
+// StatusTransition is a change of a transaction from one status to another.
+type StatusTransition struct {
+	From TransactionStatus
+	To   TransactionStatus
+}
+
+// String renders the transition as "From -> To".
+func (t StatusTransition) String() string {
+	return fmt.Sprintf("%s -> %s", t.From, t.To)
+}
+
+// IsAnomaly reports whether the transition takes back money that was already
+// paid, such as Paid -> Refunded or Delivered -> Disputed. Such transitions are
+// allowed but usually need human attention.
+func (t StatusTransition) IsAnomaly() bool {
+	return t.From.IsPaid() && (t.To.Is(StatusRefunded) || t.To.Is(StatusDisputed))
+}
+
+// TransitionError is returned when an observed status cannot follow the
+// current one, for example a stale "Pending" poll arriving after a "Paid"
+// webhook.
+type TransitionError struct {
+	StatusTransition
+}
+
+// Error implements the error interface.
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("paynow: invalid status transition %s", e.StatusTransition)
+}
+
+// Unwrap lets errors.Is match ErrInvalidTransition.
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// StatusMachine validates transitions between TransactionStatus values so that
+// a stored status never moves backwards. Create one with NewStatusMachine,
+// which starts with the standard Paynow lifecycle; configure it with Allow and
+// OnAnomaly before sharing it, after which it is safe for concurrent use.
+type StatusMachine struct {
+	allowed map[TransactionStatus]map[TransactionStatus]bool
+	hooks   []func(StatusTransition)
+}
+
+// knownStatuses lists every status the SDK knows, used to canonicalise casing.
+var knownStatuses = []TransactionStatus{
+	StatusCreated, StatusSent, StatusPending, StatusPaid, StatusAwaitingDelivery,
+	StatusDelivered, StatusCancelled, StatusFailed, StatusRefunded, StatusDisputed,
+}
+
+// canonical returns the SDK constant matching s ignoring case and surrounding
+// whitespace, or s trimmed if it is not a known status.
+func (s TransactionStatus) canonical() TransactionStatus {
+	trimmed := TransactionStatus(strings.TrimSpace(string(s)))
+	for _, known := range knownStatuses {
+		if trimmed.Is(known) {
+			return known
+		}
+	}
+	return trimmed
+}
+
+// NewStatusMachine returns a StatusMachine with the standard lifecycle:
+// in-progress statuses may move forward or to any final outcome, paid statuses
+// may progress through delivery or be refunded or disputed, a dispute may be
+// resolved as paid or refunded, and Cancelled, Failed and Refunded are final.
+func NewStatusMachine() *StatusMachine {
+	m := &StatusMachine{allowed: make(map[TransactionStatus]map[TransactionStatus]bool)}
+
+	outcomes := []TransactionStatus{StatusPaid, StatusAwaitingDelivery, StatusDelivered, StatusCancelled, StatusFailed}
+	m.Allow(StatusCreated, append([]TransactionStatus{StatusSent, StatusPending}, outcomes...)...)
+	m.Allow(StatusSent, append([]TransactionStatus{StatusPending}, outcomes...)...)
+	m.Allow(StatusPending, outcomes...)
+
+	m.Allow(StatusPaid, StatusAwaitingDelivery, StatusDelivered, StatusRefunded, StatusDisputed)
+	m.Allow(StatusAwaitingDelivery, StatusDelivered, StatusRefunded, StatusDisputed)
+	m.Allow(StatusDelivered, StatusRefunded, StatusDisputed)
+	m.Allow(StatusDisputed, StatusPaid, StatusRefunded)
+	return m
+}
+
+// Allow permits transitions from one status to each of the given statuses, and
+// returns the machine so calls can be chained.
+func (m *StatusMachine) Allow(from TransactionStatus, to ...TransactionStatus) *StatusMachine {
+	from = from.canonical()
+	if m.allowed[from] == nil {
+		m.allowed[from] = make(map[TransactionStatus]bool)
+	}
+	for _, next := range to {
+		m.allowed[from][next.canonical()] = true
+	}
+	return m
+}
+
+// OnAnomaly registers a hook called for every accepted transition for which
+// StatusTransition.IsAnomaly is true. It returns the machine so calls can be
+// chained.
+func (m *StatusMachine) OnAnomaly(hook func(StatusTransition)) *StatusMachine {
+	m.hooks = append(m.hooks, hook)
+	return m
+}
+
+// CanTransition reports whether a transaction may move from one status to
+// another. Casing is ignored.
+func (m *StatusMachine) CanTransition(from, to TransactionStatus) bool {
+	return m.allowed[from.canonical()][to.canonical()]
+}
+
+// Merge folds an observed status into the current one. It returns the status
+// to store: the observed status if the transition is allowed, or the current
+// status unchanged if nothing changed. A transition that is not allowed is
+// rejected with a *TransitionError. Anomaly hooks run for accepted anomalous
+// transitions.
+func (m *StatusMachine) Merge(current TransactionStatus, observed *StatusResponse) (TransactionStatus, error) {
+	next, err := m.merge(current, observed)
+	if err != nil {
+		return current, err
+	}
+	m.notify(StatusTransition{From: current.canonical(), To: next})
+	return next, nil
+}
+
+// merge is Merge without running hooks.
+func (m *StatusMachine) merge(current TransactionStatus, observed *StatusResponse) (TransactionStatus, error) {
+	from := current.canonical()
+	to := observed.Status.canonical()
+	if from == to {
+		return from, nil
+	}
+	if !m.CanTransition(from, to) {
+		return from, &TransitionError{StatusTransition{From: from, To: to}}
+	}
+	return to, nil
+}
+
+// notify runs the anomaly hooks for t, if it is an anomaly.
+func (m *StatusMachine) notify(t StatusTransition) {
+	if t.From == t.To || !t.IsAnomaly() {
+		return
+	}
+	for _, hook := range m.hooks {
+		hook(t)
+	}
+}
@@ -0,0 +1,94 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestStatusMachine_CanTransition(t *testing.T) {
+	m := paynow.NewStatusMachine()
+	tests := []struct {
+		from, to paynow.TransactionStatus
+		want     bool
+	}{
+		{paynow.StatusSent, paynow.StatusPending, true},
+		{paynow.StatusPending, paynow.StatusPaid, true},
+		{paynow.StatusPaid, paynow.StatusDelivered, true},
+		{paynow.StatusPaid, paynow.StatusRefunded, true},
+		{paynow.StatusDisputed, paynow.StatusPaid, true},
+		{paynow.StatusPaid, paynow.StatusPending, false},
+		{paynow.StatusCancelled, paynow.StatusPaid, false},
+		{paynow.StatusRefunded, paynow.StatusPaid, false},
+		{paynow.StatusPending, "Mystery", false},
+		{"pending", "PAID", true},
+	}
+
+	for _, tt := range tests {
+		if got := m.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestStatusMachine_Merge(t *testing.T) {
+	var anomalies []paynow.StatusTransition
+	m := paynow.NewStatusMachine().OnAnomaly(func(tr paynow.StatusTransition) {
+		anomalies = append(anomalies, tr)
+	})
+
+	got, err := m.Merge(paynow.StatusPending, &paynow.StatusResponse{Status: "paid"})
+	if err != nil || got != paynow.StatusPaid {
+		t.Errorf("Merge(Pending, paid) = %q, %v; want Paid", got, err)
+	}
+
+	got, err = m.Merge(paynow.StatusPaid, &paynow.StatusResponse{Status: paynow.StatusPending})
+	var trErr *paynow.TransitionError
+	if !errors.As(err, &trErr) || !errors.Is(err, paynow.ErrInvalidTransition) {
+		t.Fatalf("Merge(Paid, Pending) error = %v, want a *TransitionError", err)
+	}
+	if got != paynow.StatusPaid || trErr.From != paynow.StatusPaid || trErr.To != paynow.StatusPending {
+		t.Errorf("Merge(Paid, Pending) = %q, %+v; want Paid kept", got, trErr.StatusTransition)
+	}
+
+	if got, err = m.Merge(paynow.StatusPaid, &paynow.StatusResponse{Status: paynow.StatusPaid}); err != nil || got != paynow.StatusPaid {
+		t.Errorf("Merge(Paid, Paid) = %q, %v; want a no-op", got, err)
+	}
+	if len(anomalies) != 0 {
+		t.Fatalf("anomaly hook ran %d times before any anomaly", len(anomalies))
+	}
+
+	if _, err := m.Merge(paynow.StatusPaid, &paynow.StatusResponse{Status: paynow.StatusRefunded}); err != nil {
+		t.Fatalf("Merge(Paid, Refunded) error = %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].String() != "Paid -> Refunded" {
+		t.Errorf("anomalies = %v, want [Paid -> Refunded]", anomalies)
+	}
+}
+
+func TestClient_RejectsStatusRegression(t *testing.T) {
+	store := newSeededStore(t, &paynow.Transaction{Reference: "INV-1", Status: paynow.StatusPaid})
+	ctx := context.Background()
+
+	stale := signResponse(testKey,
+		field{"reference", "INV-1"},
+		field{"amount", "10.00"},
+		field{"status", "Pending"},
+	)
+	client := paynow.New("12345", testKey, paynow.WithStore(store))
+
+	resp, err := client.ProcessStatusUpdate(stale)
+	if !errors.Is(err, paynow.ErrInvalidTransition) {
+		t.Errorf("ProcessStatusUpdate(stale) error = %v, want ErrInvalidTransition", err)
+	}
+	if resp == nil || resp.Status != paynow.StatusPending {
+		t.Error("the parsed response should still be returned")
+	}
+
+	tx, _ := store.FindByReference(ctx, "INV-1")
+	if tx.Status != paynow.StatusPaid {
+		t.Errorf("stored status = %q, want Paid to be kept", tx.Status)
+	}
+}
@@ -1,6 +1,7 @@
 package paynow
 
 import (
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -23,8 +24,34 @@ func formatAmount(amount float64) string {
 	return strconv.FormatFloat(amount, 'f', 2, 64)
 }
 
+// toCents converts an amount to whole cents, rounding half away from zero.
+// Monetary arithmetic is done in cents so results are deterministic.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// fromCents converts whole cents back to an amount.
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// percentOf returns percent of cents, rounded half away from zero.
+func percentOf(cents int64, percent float64) int64 {
+	return int64(math.Round(float64(cents) * percent / 100))
+}
+
 // equalFoldTrim reports whether a and b are equal ignoring case and surrounding
 // whitespace. Paynow is inconsistent with casing on status fields.
 func equalFoldTrim(a, b string) bool {
 	return strings.EqualFold(strings.TrimSpace(a), b)
 }
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
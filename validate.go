@@ -0,0 +1,197 @@
+package paynow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits enforced by Payment.Validate.
+const (
+	// MaxReferenceLength is the longest merchant reference, in characters, the
+	// SDK sends to Paynow.
+	MaxReferenceLength = 100
+
+	// MaxTransactionAmount is the largest total the SDK sends to Paynow in a
+	// single transaction. Per-method limits may be lower.
+	MaxTransactionAmount = 100000.00
+)
+
+// phonePattern matches Zimbabwean mobile numbers in local (0771234567) or
+// international (+263771234567 or 263771234567) form.
+var phonePattern = regexp.MustCompile(`^(?:\+?263|0)7\d{8}$`)
+
+// FieldError is a single problem found by Payment.Validate.
+type FieldError struct {
+	// Field is the path of the offending field, such as "reference",
+	// "items[2].amount" or "phone".
+	Field string
+
+	// Message describes the problem, for example "is required".
+	Message string
+
+	// Err is the sentinel error for the problem, if there is one, so that
+	// errors.Is(err, ErrEmptyCart) and friends keep working on a
+	// *ValidationError.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Unwrap returns the sentinel error for the problem, if any.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every problem found with a payment, so an API layer
+// can report them all at once as form errors.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "paynow: invalid payment: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the individual field errors, so errors.Is and errors.As look
+// through a ValidationError.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// Fields maps each offending field path to its messages.
+func (e *ValidationError) Fields() map[string][]string {
+	fields := make(map[string][]string, len(e.Errors))
+	for _, fe := range e.Errors {
+		fields[fe.Field] = append(fields[fe.Field], fe.Message)
+	}
+	return fields
+}
+
+// validator accumulates field errors.
+type validator struct {
+	errs []*FieldError
+}
+
+// add records a problem with field.
+func (v *validator) add(field, message string, sentinel error) {
+	v.errs = append(v.errs, &FieldError{Field: field, Message: message, Err: sentinel})
+}
+
+// err returns a *ValidationError if any problems were recorded, or nil.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// Validate checks the payment for a web transaction and returns every problem
+// found as a *ValidationError, or nil if the payment is valid: a missing or
+// over-long reference, an invalid auth email (which is optional for web
+// payments), an empty cart, items without a title or with a negative amount or
+// quantity, negative adjustments, and a total that is not positive or is above
+// MaxTransactionAmount. A nil payment is reported as missing.
+func (p *Payment) Validate() error {
+	if p == nil {
+		return errNilPayment()
+	}
+	var v validator
+	p.validate(&v)
+	return v.err()
+}
+
+// ValidateMobile is like Validate, but for an express-checkout transaction to
+// phone using method. It additionally requires a valid auth email, a valid
+// Zimbabwean mobile number and a known payment method. Spaces and dashes in the
+// number and the method's case are ignored, as SendMobile normalizes both.
+func (p *Payment) ValidateMobile(phone string, method PaymentMethod) error {
+	if p == nil {
+		return errNilPayment()
+	}
+	var v validator
+	p.validate(&v)
+
+	if p.AuthEmail == "" {
+		v.add("authemail", "is required for mobile transactions", ErrInvalidEmail)
+	}
+	if !phonePattern.MatchString(normalizePhone(phone)) {
+		v.add("phone", "is not a valid mobile number", nil)
+	}
+	if !method.normalize().known() {
+		v.add("method", fmt.Sprintf("%q is not a supported payment method", method), nil)
+	}
+	return v.err()
+}
+
+// errNilPayment returns the *ValidationError for a nil payment.
+func errNilPayment() error {
+	var v validator
+	v.add("payment", "is required", ErrNoPayment)
+	return v.err()
+}
+
+// validate records the checks shared by Validate and ValidateMobile.
+func (p *Payment) validate(v *validator) {
+	switch n := utf8.RuneCountInString(p.Reference); {
+	case strings.TrimSpace(p.Reference) == "":
+		v.add("reference", "is required", nil)
+	case n > MaxReferenceLength:
+		v.add("reference", fmt.Sprintf("must be at most %d characters", MaxReferenceLength), nil)
+	}
+
+	if p.AuthEmail != "" && !isValidEmail(p.AuthEmail) {
+		v.add("authemail", "is not a valid email address", ErrInvalidEmail)
+	}
+
+	if len(p.cart.items) == 0 {
+		v.add("items", "must contain at least one item", ErrEmptyCart)
+	}
+	for i, item := range p.cart.items {
+		field := fmt.Sprintf("items[%d]", i)
+		if strings.TrimSpace(item.Title) == "" {
+			v.add(field+".title", "is required", nil)
+		}
+		if item.Amount < 0 {
+			v.add(field+".amount", "must not be negative", nil)
+		}
+		if item.Quantity < 0 {
+			v.add(field+".quantity", "must not be negative", nil)
+		}
+		if item.Discount.Amount < 0 || item.Discount.Percent < 0 {
+			v.add(field+".discount", "must not be negative", nil)
+		}
+	}
+	for i, adj := range p.cart.adjustments {
+		if adj.Amount < 0 || adj.Percent < 0 {
+			v.add(fmt.Sprintf("adjustments[%d]", i), "must not be negative", nil)
+		}
+	}
+
+	if len(p.cart.items) > 0 {
+		switch total := p.Total(); {
+		case total <= 0:
+			v.add("total", "must be greater than zero", ErrNonPositiveTotal)
+		case total > MaxTransactionAmount:
+			v.add("total", fmt.Sprintf("must be at most %s", formatAmount(MaxTransactionAmount)), nil)
+		}
+	}
+}
+
+// normalizePhone strips the spaces and dashes customers commonly type.
+func normalizePhone(phone string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(phone))
+}
@@ -0,0 +1,92 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestPayment_ValidateAggregatesErrors(t *testing.T) {
+	p := paynow.NewPayment(strings.Repeat("R", paynow.MaxReferenceLength+1), "not-an-email").
+		Add("", 5.00).
+		Add("Refund", -1.00)
+
+	err := p.Validate()
+	var verr *paynow.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+	}
+
+	fields := verr.Fields()
+	for _, field := range []string{"reference", "authemail", "items[0].title", "items[1].amount"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("Validate() did not report %q; got %v", field, fields)
+		}
+	}
+	if !errors.Is(err, paynow.ErrInvalidEmail) {
+		t.Error("errors.Is(err, ErrInvalidEmail) = false, want sentinels reachable through the ValidationError")
+	}
+}
+
+func TestPayment_ValidateTotalLimits(t *testing.T) {
+	huge := paynow.NewPayment("INV-1", "").Add("Car", paynow.MaxTransactionAmount+1)
+	var verr *paynow.ValidationError
+	if err := huge.Validate(); !errors.As(err, &verr) || verr.Fields()["total"] == nil {
+		t.Errorf("Validate() error = %v, want a total error", err)
+	}
+
+	if err := paidPayment().Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil for a valid payment", err)
+	}
+}
+
+func TestPayment_ValidateMobile(t *testing.T) {
+	p := paynow.NewPayment("INV-1", "").Add("Item", 10.00)
+
+	err := p.ValidateMobile("12345", "bitcoin")
+	var verr *paynow.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("ValidateMobile() error = %v, want a *ValidationError", err)
+	}
+	fields := verr.Fields()
+	for _, field := range []string{"authemail", "phone", "method"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("ValidateMobile() did not report %q; got %v", field, fields)
+		}
+	}
+
+	for _, phone := range []string{"0771234567", "+263771234567", "263 77 123 4567"} {
+		if err := paidPayment().ValidateMobile(phone, paynow.MethodEcocash); err != nil {
+			t.Errorf("ValidateMobile(%q) error = %v, want nil", phone, err)
+		}
+	}
+}
+
+func TestSendMobile_RejectsBadPhone(t *testing.T) {
+	doer := &mockDoer{}
+	_, err := newTestClient(doer).SendMobile(context.Background(), paidPayment(), "not-a-phone", paynow.MethodEcocash)
+
+	var verr *paynow.ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("SendMobile() error = %v, want a *ValidationError", err)
+	}
+	if doer.capturedURL != "" {
+		t.Error("an invalid payment must not be sent to Paynow")
+	}
+}
+
+func TestPayment_ValidateNil(t *testing.T) {
+	var p *paynow.Payment
+	for name, err := range map[string]error{
+		"Validate":       p.Validate(),
+		"ValidateMobile": p.ValidateMobile("0771234567", paynow.MethodEcocash),
+	} {
+		var verr *paynow.ValidationError
+		if !errors.As(err, &verr) || !errors.Is(err, paynow.ErrNoPayment) {
+			t.Errorf("%s() on a nil payment error = %v, want a *ValidationError matching ErrNoPayment", name, err)
+		}
+	}
+}
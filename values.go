@@ -1,117 +1,76 @@
 package paynow
 
 import (
-	"net/url"
-	"strings"
+	"context"
 
-	"github.com/IamTyrone/paynow-go/internal/hash"
+	"github.com/IamTyrone/paynow-go/signature"
 )
 
 // orderedValues is an ordered set of key/value pairs. Order matters for Paynow's
 // hashing scheme, so unlike url.Values (a map) this preserves insertion order
-// both for building requests and for parsing responses.
+// both for building requests and for parsing responses. It is backed by
+// signature.Fields, so requests and responses are parsed, encoded and hashed
+// exactly as the public signature package does.
 type orderedValues struct {
-	keys   []string
-	values map[string]string
+	fields signature.Fields
 }
 
 // newOrderedValues returns an empty orderedValues ready for use.
 func newOrderedValues() *orderedValues {
-	return &orderedValues{values: make(map[string]string)}
+	return &orderedValues{}
 }
 
 // set appends a key/value pair, preserving order. Keys are assumed unique, which
 // holds for every Paynow request and response.
 func (o *orderedValues) set(key, value string) {
-	o.keys = append(o.keys, key)
-	o.values[key] = value
+	o.fields = append(o.fields, signature.Field{Key: key, Value: value})
 }
 
 // get returns the value for key and whether it was present.
 func (o *orderedValues) get(key string) (string, bool) {
-	v, ok := o.values[key]
-	return v, ok
+	return o.fields.Get(key)
 }
 
 // signingValues returns every value except the hash field, in order, ready to be
 // fed to the hashing routine.
 func (o *orderedValues) signingValues() []string {
-	out := make([]string, 0, len(o.keys))
-	for _, k := range o.keys {
-		if strings.EqualFold(k, "hash") {
-			continue
-		}
-		out = append(out, o.values[k])
-	}
-	return out
+	return o.fields.Values()
 }
 
 // asMap returns the values as a plain map, exposed on responses via the Raw field.
 func (o *orderedValues) asMap() map[string]string {
-	m := make(map[string]string, len(o.values))
-	for k, v := range o.values {
-		m[k] = v
+	m := make(map[string]string, len(o.fields))
+	for _, f := range o.fields {
+		m[f.Key] = f.Value
 	}
 	return m
 }
 
 // encode renders the values as an application/x-www-form-urlencoded body,
 // preserving order. Standard library encoders sort keys, which would break the
-// hash, so the body is assembled by hand here.
+// hash.
 func (o *orderedValues) encode() string {
-	var b strings.Builder
-	for i, k := range o.keys {
-		if i > 0 {
-			b.WriteByte('&')
-		}
-		b.WriteString(url.QueryEscape(k))
-		b.WriteByte('=')
-		b.WriteString(url.QueryEscape(o.values[k]))
-	}
-	return b.String()
+	return o.fields.Encode()
 }
 
-// verifyHash checks the hash field against a hash computed from the other
-// values. It returns ErrMissingHash when no hash is present and ErrHashMismatch
+// verifyHash checks the hash field against the other values with v. It returns
+// ErrMissingHash when no hash is present and an error matching ErrHashMismatch
 // when the hashes differ.
-func (o *orderedValues) verifyHash(integrationKey string) error {
+func (o *orderedValues) verifyHash(ctx context.Context, v Verifier) error {
 	received, ok := o.get("hash")
 	if !ok {
 		return ErrMissingHash
 	}
-	if !hash.Equal(received, o.signingValues(), integrationKey) {
-		return ErrHashMismatch
-	}
-	return nil
+	return v.Verify(ctx, o.signingValues(), received)
 }
 
 // parseResponse parses a raw application/x-www-form-urlencoded body from Paynow
-// into ordered, URL-decoded key/value pairs. Order is taken from the body so it
-// can be used to reconstruct and verify the hash.
+// into ordered, URL-decoded key/value pairs with signature.Parse. Order is taken
+// from the body so it can be used to reconstruct and verify the hash.
 func parseResponse(body string) (*orderedValues, error) {
-	body = strings.TrimSpace(body)
-	body = strings.TrimPrefix(body, "?")
-
-	ov := newOrderedValues()
-	if body == "" {
-		return ov, nil
-	}
-
-	for _, pair := range strings.Split(body, "&") {
-		if pair == "" {
-			continue
-		}
-		rawKey, rawValue, _ := strings.Cut(pair, "=")
-
-		key, err := url.QueryUnescape(rawKey)
-		if err != nil {
-			return nil, err
-		}
-		value, err := url.QueryUnescape(rawValue)
-		if err != nil {
-			return nil, err
-		}
-		ov.set(key, value)
+	fields, err := signature.Parse(body)
+	if err != nil {
+		return nil, err
 	}
-	return ov, nil
+	return &orderedValues{fields: fields}, nil
 }
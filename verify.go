@@ -0,0 +1,102 @@
+package paynow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Expectation describes what a status response for a transaction must match.
+// Build one from the original payment with ExpectPayment, or from a stored
+// transaction with ExpectTransaction, and check responses with Verify.
+type Expectation struct {
+	// Reference is the merchant reference the response must carry. Responses
+	// without a reference are not checked against it.
+	Reference string
+
+	// Amount is the amount the response must report, compared to the cent.
+	Amount float64
+
+	// PollURL, if set, is the poll URL the response must carry when it carries
+	// one.
+	PollURL string
+
+	// Currency, if set, is the currency the response must report when Paynow
+	// includes a "currency" field. Comparison ignores case.
+	Currency string
+}
+
+// ExpectPayment returns the Expectation for the payment that was sent to
+// Paynow: its reference and total.
+func ExpectPayment(payment *Payment) Expectation {
+	return Expectation{Reference: payment.Reference, Amount: payment.Total()}
+}
+
+// ExpectTransaction returns the Expectation for a transaction saved in a
+// Store: its reference, amount and poll URL.
+func ExpectTransaction(tx *Transaction) Expectation {
+	return Expectation{Reference: tx.Reference, Amount: tx.Amount, PollURL: tx.PollURL}
+}
+
+// Verify checks resp against the expectation, returning a *MismatchError for
+// the first field that differs. Use it before treating a Paid status as final,
+// so an underpaid or tampered transaction is not fulfilled.
+func (e Expectation) Verify(resp *StatusResponse) error {
+	if resp.Reference != "" && resp.Reference != e.Reference {
+		return &MismatchError{Field: "reference", Expected: e.Reference, Actual: resp.Reference}
+	}
+	if e.PollURL != "" && resp.PollURL != "" && resp.PollURL != e.PollURL {
+		return &MismatchError{Field: "pollurl", Expected: e.PollURL, Actual: resp.PollURL}
+	}
+	if toCents(resp.Amount) != toCents(e.Amount) {
+		return &MismatchError{Field: "amount", Expected: formatAmount(e.Amount), Actual: formatAmount(resp.Amount)}
+	}
+	if currency, ok := resp.Raw["currency"]; ok && e.Currency != "" && !equalFoldTrim(currency, strings.TrimSpace(e.Currency)) {
+		return &MismatchError{Field: "currency", Expected: e.Currency, Actual: currency}
+	}
+	return nil
+}
+
+// WithStatusVerification makes PollTransaction and ProcessStatusUpdate verify
+// every status response against the transaction saved in the Client's Store
+// (see ExpectTransaction) before recording it. A response that does not match
+// is returned with a *MismatchError and is not recorded. It has no effect
+// without WithStore.
+func WithStatusVerification() Option {
+	return func(c *Client) { c.verifyStatus = true }
+}
+
+// WithExpectedCurrency sets the currency, such as "USD", that status responses
+// checked against the Client's Store (by WithStatusVerification and
+// ProcessStatusUpdateOnce) must report when Paynow includes a "currency" field.
+// A Paynow integration settles in a single currency, so set it to that of the
+// integration ID.
+func WithExpectedCurrency(currency string) Option {
+	return func(c *Client) { c.currency = strings.TrimSpace(currency) }
+}
+
+// verifyStored checks resp against the transaction saved in the Client's Store
+// and the Client's expected currency. The transaction is looked up by pollURL
+// when given, so a response carrying another transaction's reference is
+// caught. It does nothing without a Store.
+func (c *Client) verifyStored(ctx context.Context, pollURL string, resp *StatusResponse) error {
+	if c.store == nil {
+		return nil
+	}
+
+	var (
+		tx  *Transaction
+		err error
+	)
+	if pollURL != "" {
+		tx, err = c.store.FindByPollURL(ctx, pollURL)
+	} else {
+		tx, err = c.findTransaction(ctx, "", resp)
+	}
+	if err != nil {
+		return fmt.Errorf("paynow: cannot verify status: %w", err)
+	}
+	expect := ExpectTransaction(tx)
+	expect.Currency = c.currency
+	return expect.Verify(resp)
+}
@@ -0,0 +1,84 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestExpectation_Verify(t *testing.T) {
+	payment := paynow.NewPayment("INV-1", "buyer@example.com").Add("Item", 5.00, 2)
+	expect := paynow.ExpectPayment(payment)
+	expect.Currency = "USD"
+
+	tests := []struct {
+		name  string
+		resp  paynow.StatusResponse
+		field string
+	}{
+		{"match", paynow.StatusResponse{Reference: "INV-1", Amount: 10.00}, ""},
+		{"match within a cent", paynow.StatusResponse{Reference: "INV-1", Amount: 10.001}, ""},
+		{"no reference", paynow.StatusResponse{Amount: 10.00}, ""},
+		{"currency matches", paynow.StatusResponse{Reference: "INV-1", Amount: 10.00, Raw: map[string]string{"currency": "usd"}}, ""},
+		{"underpaid", paynow.StatusResponse{Reference: "INV-1", Amount: 9.99}, "amount"},
+		{"wrong reference", paynow.StatusResponse{Reference: "INV-2", Amount: 10.00}, "reference"},
+		{"wrong currency", paynow.StatusResponse{Reference: "INV-1", Amount: 10.00, Raw: map[string]string{"currency": "ZWG"}}, "currency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expect.Verify(&tt.resp)
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var mismatch *paynow.MismatchError
+			if !errors.As(err, &mismatch) || mismatch.Field != tt.field {
+				t.Errorf("Verify() error = %v, want a %s mismatch", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestPollTransaction_StatusVerification(t *testing.T) {
+	pollURL := "https://www.paynow.co.zw/interface/poll/1"
+	client, store := newStoreClient(t, 50.00)
+	client = client.With(
+		paynow.WithHTTPClient(&mockDoer{response: paidStatusBody()}),
+		paynow.WithStatusVerification(),
+	)
+
+	resp, err := client.PollTransaction(context.Background(), pollURL)
+	if !errors.Is(err, paynow.ErrMismatch) {
+		t.Fatalf("PollTransaction() error = %v, want ErrMismatch", err)
+	}
+	if resp == nil || !resp.Paid {
+		t.Error("the response should be returned alongside the mismatch")
+	}
+	if tx, _ := store.FindByReference(context.Background(), "INV-1"); tx.Status.IsPaid() {
+		t.Error("an unverified status must not be recorded")
+	}
+}
+
+func TestProcessStatusUpdateOnce_ExpectedCurrency(t *testing.T) {
+	client, _ := newStoreClient(t, 10.00)
+	client = client.With(paynow.WithExpectedCurrency("USD"))
+	body := signResponse(testKey,
+		field{"reference", "INV-1"},
+		field{"amount", "10.00"},
+		field{"currency", "ZWG"},
+		field{"pollurl", "https://www.paynow.co.zw/interface/poll/1"},
+		field{"status", "Paid"},
+	)
+
+	_, err := client.ProcessStatusUpdateOnce(context.Background(), body)
+	var mismatch *paynow.MismatchError
+	if !errors.As(err, &mismatch) || mismatch.Field != "currency" {
+		t.Errorf("ProcessStatusUpdateOnce() error = %v, want a currency mismatch", err)
+	}
+}
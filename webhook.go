@@ -0,0 +1,69 @@
+package paynow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ProcessStatusUpdateOnce is an idempotent companion to ProcessStatusUpdate for
+// result-URL handlers. It requires a Store (see WithStore) and returns
+// ErrNoStore without one. After verifying the hash it:
+//
+//   - checks that the reference, poll URL and amount match the transaction
+//     that was originally initiated (see ExpectTransaction), returning a
+//     *MismatchError if they do not;
+//   - claims the (reference, status, hash) tuple in the Client's DedupStore,
+//     returning the response with ErrReplayedUpdate if it was already
+//     processed, or ErrUpdateInProgress if it is still being processed;
+//   - records the status in the Client's Store, as ProcessStatusUpdate does,
+//     and only then marks the update as processed.
+//
+// Only a nil error means the update is genuine and new. Handlers should still
+// acknowledge ErrReplayedUpdate with a 200 so Paynow stops retrying, but not
+// ErrUpdateInProgress, so Paynow retries if the first attempt fails.
+func (c *Client) ProcessStatusUpdateOnce(ctx context.Context, rawBody string) (*StatusResponse, error) {
+	resp, err := c.parseStatusUpdate(ctx, rawBody)
+	if err != nil {
+		return resp, err
+	}
+
+	if c.store == nil {
+		return resp, ErrNoStore
+	}
+	if err := c.verifyStored(ctx, "", resp); err != nil {
+		return resp, err
+	}
+
+	key := dedupKey(resp)
+	claimed, err := c.dedup.Claim(ctx, key, dedupClaimLease)
+	if errors.Is(err, ErrUpdateInProgress) {
+		return resp, ErrUpdateInProgress
+	}
+	if err != nil {
+		return resp, fmt.Errorf("paynow: failed to check for replayed update: %w", err)
+	}
+	if !claimed {
+		return resp, ErrReplayedUpdate
+	}
+
+	if err := c.recordStatus(ctx, "", resp); err != nil {
+		// Let a retry of this update be processed rather than flagged.
+		_ = c.dedup.Release(ctx, key)
+		return resp, err
+	}
+	if err := c.dedup.Done(ctx, key, c.dedupTTL); err != nil {
+		return resp, fmt.Errorf("paynow: failed to mark update as processed: %w", err)
+	}
+	return resp, nil
+}
+
+// dedupKey identifies a status update for replay detection.
+func dedupKey(resp *StatusResponse) string {
+	return strings.Join([]string{
+		resp.Reference,
+		string(resp.Status.canonical()),
+		strings.ToUpper(resp.Hash),
+	}, "|")
+}
@@ -0,0 +1,153 @@
+package paynow_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IamTyrone/paynow-go"
+)
+
+func TestProcessStatusUpdateOnce_FlagsReplays(t *testing.T) {
+	client, store := newStoreClient(t, 10.00)
+	ctx := context.Background()
+
+	resp, err := client.ProcessStatusUpdateOnce(ctx, paidStatusBody())
+	if err != nil {
+		t.Fatalf("ProcessStatusUpdateOnce() error = %v", err)
+	}
+	if !resp.Paid {
+		t.Error("expected a paid transaction")
+	}
+	if tx, _ := store.FindByReference(ctx, "INV-1"); !tx.Status.IsPaid() {
+		t.Errorf("stored status = %q, want Paid", tx.Status)
+	}
+
+	resp, err = client.ProcessStatusUpdateOnce(ctx, paidStatusBody())
+	if !errors.Is(err, paynow.ErrReplayedUpdate) {
+		t.Errorf("second ProcessStatusUpdateOnce() error = %v, want ErrReplayedUpdate", err)
+	}
+	if resp == nil {
+		t.Error("a replayed update should still return the parsed response")
+	}
+}
+
+func TestProcessStatusUpdateOnce_AmountMismatch(t *testing.T) {
+	client, store := newStoreClient(t, 25.00)
+	ctx := context.Background()
+
+	_, err := client.ProcessStatusUpdateOnce(ctx, paidStatusBody())
+	var mismatch *paynow.MismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, paynow.ErrMismatch) {
+		t.Fatalf("ProcessStatusUpdateOnce() error = %v, want a *MismatchError", err)
+	}
+	if mismatch.Field != "amount" || mismatch.Expected != "25.00" || mismatch.Actual != "10.00" {
+		t.Errorf("mismatch = %+v", mismatch)
+	}
+	if tx, _ := store.FindByReference(ctx, "INV-1"); tx.Status.IsPaid() {
+		t.Error("a mismatched update must not be recorded")
+	}
+}
+
+func TestProcessStatusUpdateOnce_UnknownTransaction(t *testing.T) {
+	client := paynow.New("12345", testKey, paynow.WithStore(paynow.NewMemoryStore()))
+	if _, err := client.ProcessStatusUpdateOnce(context.Background(), paidStatusBody()); !errors.Is(err, paynow.ErrTransactionNotFound) {
+		t.Errorf("ProcessStatusUpdateOnce() error = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+func TestProcessStatusUpdateOnce_RequiresStore(t *testing.T) {
+	client := paynow.New("12345", testKey)
+	resp, err := client.ProcessStatusUpdateOnce(context.Background(), paidStatusBody())
+	if !errors.Is(err, paynow.ErrNoStore) {
+		t.Errorf("ProcessStatusUpdateOnce() error = %v, want ErrNoStore", err)
+	}
+	if resp == nil {
+		t.Error("an unchecked update should still return the parsed response")
+	}
+}
+
+// failingOnceStore blocks the first status update until released, then fails
+// it. Later updates go through.
+type failingOnceStore struct {
+	paynow.Store
+	entered chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (s *failingOnceStore) UpdateStatus(ctx context.Context, reference string, status paynow.TransactionStatus, paynowReference string) error {
+	if s.calls.Add(1) == 1 {
+		close(s.entered)
+		<-s.release
+		return errors.New("database unavailable")
+	}
+	return s.Store.UpdateStatus(ctx, reference, status, paynowReference)
+}
+
+func TestProcessStatusUpdateOnce_RetryWhileFirstAttemptFails(t *testing.T) {
+	_, mem := newStoreClient(t, 10.00)
+	store := &failingOnceStore{Store: mem, entered: make(chan struct{}), release: make(chan struct{})}
+	client := paynow.New("12345", testKey, paynow.WithStore(store))
+	ctx := context.Background()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.ProcessStatusUpdateOnce(ctx, paidStatusBody())
+		first <- err
+	}()
+	<-store.entered
+
+	if _, err := client.ProcessStatusUpdateOnce(ctx, paidStatusBody()); !errors.Is(err, paynow.ErrUpdateInProgress) {
+		t.Errorf("retry during the first attempt error = %v, want ErrUpdateInProgress", err)
+	}
+
+	close(store.release)
+	if err := <-first; err == nil {
+		t.Fatal("first attempt should fail")
+	}
+
+	if _, err := client.ProcessStatusUpdateOnce(ctx, paidStatusBody()); err != nil {
+		t.Fatalf("retry after the failure error = %v, want it processed", err)
+	}
+	if tx, _ := mem.FindByReference(ctx, "INV-1"); !tx.Status.IsPaid() {
+		t.Errorf("stored status = %q, want Paid", tx.Status)
+	}
+}
+
+func TestMemoryDedupStore_TTL(t *testing.T) {
+	store := paynow.NewMemoryDedupStore()
+	ctx := context.Background()
+
+	if ok, _ := store.Claim(ctx, "k", time.Minute); !ok {
+		t.Fatal("first Claim() should succeed")
+	}
+	if _, err := store.Claim(ctx, "k", time.Minute); !errors.Is(err, paynow.ErrUpdateInProgress) {
+		t.Errorf("Claim() of an in-progress key error = %v, want ErrUpdateInProgress", err)
+	}
+
+	_ = store.Done(ctx, "k", 10*time.Millisecond)
+	if ok, err := store.Claim(ctx, "k", time.Minute); ok || err != nil {
+		t.Errorf("Claim() of a done key = %v, %v; want false, nil", ok, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := store.Claim(ctx, "k", time.Minute); !ok {
+		t.Error("Claim() after the TTL should succeed")
+	}
+
+	_ = store.Release(ctx, "k")
+	if ok, _ := store.Claim(ctx, "k", time.Minute); !ok {
+		t.Error("Claim() after Release() should succeed")
+	}
+
+	if ok, _ := store.Claim(ctx, "lease", 10*time.Millisecond); !ok {
+		t.Fatal("Claim() should succeed")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ok, _ := store.Claim(ctx, "lease", time.Minute); !ok {
+		t.Error("Claim() after an abandoned claim's lease should succeed")
+	}
+}